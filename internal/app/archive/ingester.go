@@ -0,0 +1,150 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/damonto/sigmo/internal/app/events"
+	"github.com/damonto/sigmo/internal/pkg/metrics"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+// Ingester keeps the archive in sync with the messages stored on every modem
+// and follows the delivery of the messages they send. It holds the messaging
+// subscription of every modem and publishes the archived messages as events.
+type Ingester struct {
+	store   *Store
+	manager *modem.Manager
+	hub     *events.Hub
+	mu      sync.Mutex
+	cancels map[dbus.ObjectPath]context.CancelFunc
+}
+
+func NewIngester(store *Store, manager *modem.Manager, hub *events.Hub) *Ingester {
+	return &Ingester{
+		store:   store,
+		manager: manager,
+		hub:     hub,
+		cancels: make(map[dbus.ObjectPath]context.CancelFunc),
+	}
+}
+
+func (i *Ingester) Run(ctx context.Context) error {
+	modems, err := i.manager.Modems()
+	if err != nil {
+		return fmt.Errorf("listing modems: %w", err)
+	}
+	for path, m := range modems {
+		i.addModem(ctx, path, m)
+	}
+
+	unsubscribe, err := i.manager.Subscribe(func(event modem.ModemEvent) error {
+		switch event.Type {
+		case modem.ModemEventAdded:
+			if event.Modem == nil {
+				return nil
+			}
+			i.addModem(ctx, event.Path, event.Modem)
+		case modem.ModemEventRemoved:
+			i.removeModem(event.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("subscribing to modem manager: %w", err)
+	}
+	defer unsubscribe()
+
+	<-ctx.Done()
+	i.mu.Lock()
+	for path, cancel := range i.cancels {
+		cancel()
+		delete(i.cancels, path)
+	}
+	i.mu.Unlock()
+	return nil
+}
+
+func (i *Ingester) addModem(ctx context.Context, path dbus.ObjectPath, m *modem.Modem) {
+	if ctx.Err() != nil {
+		return
+	}
+	i.mu.Lock()
+	if cancel, ok := i.cancels[path]; ok {
+		cancel()
+	}
+	modemCtx, cancel := context.WithCancel(ctx)
+	i.cancels[path] = cancel
+	i.mu.Unlock()
+
+	go func() {
+		if err := i.store.Ingest(m); err != nil {
+			slog.Error("failed to archive modem messages", "error", err, "modem", m.EquipmentIdentifier)
+		}
+		if err := m.Messaging().Subscribe(modemCtx, func(message *modem.SMS) error {
+			saved, err := i.store.Save(m.EquipmentIdentifier, message)
+			if err != nil {
+				// Still publish the message, the event stream does not need
+				// the archive.
+				slog.Error("failed to archive SMS", "modem", m.EquipmentIdentifier, "error", err)
+				saved = MessageOf(message)
+			}
+			eventType := events.TypeSMSSent
+			if saved.Incoming {
+				metrics.SMSReceived(m.EquipmentIdentifier)
+				eventType = events.TypeSMSReceived
+			}
+			i.hub.Publish(eventType, m.EquipmentIdentifier, events.SMSData{
+				ID:        saved.ID,
+				Number:    saved.Number,
+				Text:      saved.Text,
+				Timestamp: saved.Timestamp,
+				Incoming:  saved.Incoming,
+				Status:    saved.Status,
+			})
+			return nil
+		}); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("modem archive subscription stopped", "error", err, "modem", m.EquipmentIdentifier)
+		}
+	}()
+
+	go func() {
+		if err := m.Messaging().WatchDeliveries(modemCtx, func(sms *modem.SMS) {
+			i.saveStatus(m, sms)
+		}); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("modem delivery tracking stopped", "error", err, "modem", m.EquipmentIdentifier)
+		}
+	}()
+}
+
+// saveStatus archives the new status of a sent message and publishes it.
+func (i *Ingester) saveStatus(m *modem.Modem, sms *modem.SMS) {
+	message, err := i.store.Save(m.EquipmentIdentifier, sms)
+	if err != nil {
+		slog.Warn("failed to archive SMS status", "modem", m.EquipmentIdentifier, "error", err)
+		return
+	}
+	i.hub.Publish(events.TypeSMSStatus, m.EquipmentIdentifier, events.SMSData{
+		ID:        message.ID,
+		Number:    message.Number,
+		Text:      message.Text,
+		Timestamp: message.Timestamp,
+		Status:    message.Status,
+		Reason:    message.Reason,
+	})
+}
+
+func (i *Ingester) removeModem(path dbus.ObjectPath) {
+	i.mu.Lock()
+	cancel := i.cancels[path]
+	delete(i.cancels, path)
+	i.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
@@ -0,0 +1,429 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+var (
+	messagesBucket = []byte("messages")
+	digestsBucket  = []byte("digests")
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Store is an on-disk archive of every SMS seen on any modem.
+//
+// Messages are grouped per modem and keyed by timestamp followed by a
+// store-wide sequence number, so a cursor walk over a modem bucket yields
+// messages in chronological order.
+type Store struct {
+	db *bolt.DB
+}
+
+type Message struct {
+	ID        uint64    `json:"id"`
+	Number    string    `json:"number"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+	State     string    `json:"state"`
+	Incoming  bool      `json:"incoming"`
+	Path      string    `json:"path"`
+	// Status is received for incoming messages and queued, sent, delivered
+	// or failed for outgoing ones. Reason explains a failed delivery, or a
+	// delivery the SMSC is still retrying.
+	Status      string    `json:"status,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	DeliveredAt time.Time `json:"deliveredAt,omitzero"`
+	key         []byte
+}
+
+const (
+	StatusReceived  = "received"
+	StatusQueued    = "queued"
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+type Query struct {
+	Participant string
+	Search      string
+	From        time.Time
+	To          time.Time
+	Cursor      string
+	Limit       int
+}
+
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating archive directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(messagesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(digestsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing archive: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save records the SMS for the given modem. A message that has already been
+// archived is updated in place, so repeated ingestion of the same SMS from
+// Messaging.List and Messaging.Subscribe never creates duplicates.
+func (s *Store) Save(modemID string, sms *modem.SMS) (*Message, error) {
+	var saved *Message
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		saved, err = save(tx, modemID, sms)
+		return err
+	})
+	return saved, err
+}
+
+func save(tx *bolt.Tx, modemID string, sms *modem.SMS) (*Message, error) {
+	messages, err := tx.Bucket(messagesBucket).CreateBucketIfNotExists([]byte(modemID))
+	if err != nil {
+		return nil, err
+	}
+	digests, err := tx.Bucket(digestsBucket).CreateBucketIfNotExists([]byte(modemID))
+	if err != nil {
+		return nil, err
+	}
+	digest := digestOf(sms)
+	if key := digests.Get(digest); key != nil {
+		message, err := decodeMessage(key, messages.Get(key))
+		if err != nil {
+			return nil, err
+		}
+		previous := *message
+		message.State = stateOf(sms)
+		message.Path = string(sms.Path())
+		message.setStatus(sms)
+		if message.State == previous.State && message.Path == previous.Path && message.Status == previous.Status &&
+			message.Reason == previous.Reason && message.DeliveredAt.Equal(previous.DeliveredAt) {
+			return message, nil
+		}
+		return message, putMessage(messages, message)
+	}
+
+	id, err := tx.Bucket(messagesBucket).NextSequence()
+	if err != nil {
+		return nil, err
+	}
+	message := MessageOf(sms)
+	message.ID = id
+	message.key = messageKey(message.Timestamp, id)
+	if err := digests.Put(digest, message.key); err != nil {
+		return nil, err
+	}
+	return message, putMessage(messages, message)
+}
+
+// MessageOf returns the SMS as a message that is not archived, it has no ID.
+// Messages without a timestamp get the current time.
+func MessageOf(sms *modem.SMS) *Message {
+	timestamp := sms.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	message := &Message{
+		Number:    strings.TrimSpace(sms.Number),
+		Text:      sms.Text,
+		Timestamp: timestamp,
+		State:     stateOf(sms),
+		Incoming:  isIncoming(sms),
+		Path:      string(sms.Path()),
+	}
+	message.setStatus(sms)
+	return message
+}
+
+// Ingest archives every message currently stored on the modem in a single
+// transaction. Messages that did not change are not written again.
+func (s *Store) Ingest(m *modem.Modem) error {
+	messages, err := m.Messaging().List()
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, sms := range messages {
+			if _, err := save(tx, m.EquipmentIdentifier, sms); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Messages returns the messages matching the query, newest first. The
+// returned cursor is empty when there are no older messages left.
+func (s *Store) Messages(modemID string, query Query) ([]Message, string, error) {
+	var (
+		result []Message
+		next   string
+	)
+	err := s.view(modemID, query, func(message *Message) bool {
+		if query.Limit > 0 && len(result) == query.Limit {
+			next = encodeCursor(result[len(result)-1].key)
+			return false
+		}
+		result = append(result, *message)
+		return true
+	})
+	return result, next, err
+}
+
+// Conversations returns the latest matching message of every participant,
+// ordered by that message, newest first.
+func (s *Store) Conversations(modemID string, query Query) ([]Message, string, error) {
+	var (
+		result []Message
+		next   string
+	)
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	seen := make(map[string]struct{})
+	query.Cursor = ""
+	err = s.view(modemID, query, func(message *Message) bool {
+		if _, ok := seen[message.Number]; ok {
+			return true
+		}
+		seen[message.Number] = struct{}{}
+		if after != nil && bytes.Compare(message.key, after) >= 0 {
+			return true
+		}
+		if query.Limit > 0 && len(result) == query.Limit {
+			next = encodeCursor(result[len(result)-1].key)
+			return false
+		}
+		result = append(result, *message)
+		return true
+	})
+	return result, next, err
+}
+
+func (s *Store) DeleteByParticipant(modemID string, participant string) error {
+	participant = strings.TrimSpace(participant)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		messages := tx.Bucket(messagesBucket).Bucket([]byte(modemID))
+		digests := tx.Bucket(digestsBucket).Bucket([]byte(modemID))
+		if messages == nil || digests == nil {
+			return nil
+		}
+		deleted := make(map[string]struct{})
+		c := messages.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			message, err := decodeMessage(k, v)
+			if err != nil {
+				return err
+			}
+			if message.Number != participant {
+				continue
+			}
+			deleted[string(k)] = struct{}{}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		dc := digests.Cursor()
+		for k, v := dc.First(); k != nil; k, v = dc.Next() {
+			if _, ok := deleted[string(v)]; !ok {
+				continue
+			}
+			if err := dc.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// view walks the modem bucket from the newest message to the oldest one and
+// calls fn for every message matching the query until fn returns false.
+func (s *Store) view(modemID string, query Query, fn func(message *Message) bool) error {
+	before, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return err
+	}
+	terms := strings.Fields(strings.ToLower(query.Search))
+	participant := strings.TrimSpace(query.Participant)
+	return s.db.View(func(tx *bolt.Tx) error {
+		messages := tx.Bucket(messagesBucket).Bucket([]byte(modemID))
+		if messages == nil {
+			return nil
+		}
+		c := messages.Cursor()
+		k, v := c.Last()
+		if before != nil {
+			k, v = c.Seek(before)
+			if k == nil {
+				k, v = c.Last()
+			}
+			for k != nil && bytes.Compare(k, before) >= 0 {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			message, err := decodeMessage(k, v)
+			if err != nil {
+				return err
+			}
+			if !query.To.IsZero() && message.Timestamp.After(query.To) {
+				continue
+			}
+			if !query.From.IsZero() && message.Timestamp.Before(query.From) {
+				// Keys are ordered by timestamp, nothing older can match.
+				return nil
+			}
+			if participant != "" && message.Number != participant {
+				continue
+			}
+			if !matches(message, terms) {
+				continue
+			}
+			if !fn(message) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+func matches(message *Message, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	text := strings.ToLower(message.Text)
+	number := strings.ToLower(message.Number)
+	for _, term := range terms {
+		if !strings.Contains(text, term) && !strings.Contains(number, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func putMessage(bucket *bolt.Bucket, message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	return bucket.Put(message.key, data)
+}
+
+func decodeMessage(key []byte, data []byte) (*Message, error) {
+	var message Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, fmt.Errorf("decoding message: %w", err)
+	}
+	message.key = append([]byte(nil), key...)
+	return &message, nil
+}
+
+func messageKey(timestamp time.Time, id uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], uint64(timestamp.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], id)
+	return key
+}
+
+// digestOf identifies an SMS. Received messages are told apart by their
+// timestamp, as ModemManager reuses object paths after a restart. Outgoing
+// messages have none and keep the digest of the path they were first archived
+// under: their message reference wraps after 255 and would merge repeated
+// messages to the same number.
+func digestOf(sms *modem.SMS) []byte {
+	id := string(sms.Path())
+	if isIncoming(sms) && !sms.Timestamp.IsZero() {
+		id = sms.Timestamp.UTC().Format(time.RFC3339)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%t\x00%s", strings.TrimSpace(sms.Number), sms.Text, isIncoming(sms), id)
+	return h.Sum(nil)
+}
+
+func isIncoming(sms *modem.SMS) bool {
+	return sms.State == modem.SMSStateReceived || sms.State == modem.SMSStateReceiving
+}
+
+// setStatus derives the status from the SMS and its delivery report. A
+// status is never downgraded, e.g. when the SMS is ingested again after
+// ModemManager forgot about its delivery report.
+func (m *Message) setStatus(sms *modem.SMS) {
+	status, reason := statusOf(sms)
+	if m.Status == StatusDelivered || m.Status == StatusFailed {
+		if status != StatusDelivered && status != StatusFailed {
+			return
+		}
+	}
+	m.Status, m.Reason = status, reason
+	if status == StatusDelivered {
+		m.DeliveredAt = sms.DischargeTimestamp
+	}
+}
+
+func statusOf(sms *modem.SMS) (string, string) {
+	if isIncoming(sms) {
+		return StatusReceived, ""
+	}
+	if sms.State != modem.SMSStateSent {
+		return StatusQueued, ""
+	}
+	switch state := sms.DeliveryState; {
+	case state.Delivered():
+		return StatusDelivered, ""
+	case state.Failed():
+		return StatusFailed, state.String()
+	case state == modem.SMSDeliveryStateUnknown:
+		return StatusSent, ""
+	default:
+		return StatusSent, state.String()
+	}
+}
+
+func stateOf(sms *modem.SMS) string {
+	return strings.ToLower(sms.State.String())
+}
+
+func encodeCursor(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+func decodeCursor(cursor string) ([]byte, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(key) != 16 {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
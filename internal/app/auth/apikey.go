@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+)
+
+const (
+	apiKeyPrefix       = "sigmo_"
+	minConfigKeyLength = 32
+)
+
+const (
+	ScopeAll            = "*"
+	ScopeModemsRead     = "modems:read"
+	ScopeModemsManage   = "modems:manage"
+	ScopeMessagesRead   = "messages:read"
+	ScopeMessagesSend   = "messages:send"
+	ScopeMessagesDelete = "messages:delete"
+	ScopeUSSD           = "ussd"
+	ScopeNetworks       = "networks"
+	ScopeEsimRead       = "esim:read"
+	ScopeEsimManage     = "esim:manage"
+	ScopeMetrics        = "metrics"
+	ScopeCalls          = "calls"
+)
+
+var Scopes = []string{
+	ScopeAll,
+	ScopeModemsRead,
+	ScopeModemsManage,
+	ScopeMessagesRead,
+	ScopeMessagesSend,
+	ScopeMessagesDelete,
+	ScopeUSSD,
+	ScopeNetworks,
+	ScopeEsimRead,
+	ScopeEsimManage,
+	ScopeMetrics,
+	ScopeCalls,
+}
+
+var apiKeysBucket = []byte("api_keys")
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyReadOnly = errors.New("api key is defined in the config file")
+	ErrInvalidScope   = errors.New("invalid scope")
+)
+
+// APIKey is a long-lived credential for automation. Like sessions, only the
+// hash of the key is stored. Keys without modems may use every modem.
+type APIKey struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Scopes     []string  `json:"scopes"`
+	Modems     []string  `json:"modems,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Config     bool      `json:"-"`
+	flushedAt  time.Time
+}
+
+// Allows reports whether the key grants the scope.
+func (k APIKey) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, ScopeAll) || slices.Contains(k.Scopes, scope)
+}
+
+// AllowsModem reports whether the key may act on the modem.
+func (k APIKey) AllowsModem(id string) bool {
+	return len(k.Modems) == 0 || slices.Contains(k.Modems, id)
+}
+
+// AddConfigKeys registers the API keys of the config file. They are kept in
+// memory only and cannot be deleted through the API.
+func (s *Store) AddConfigKeys(keys []config.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		name := strings.TrimSpace(key.Name)
+		if name == "" {
+			return errors.New("api key name is required")
+		}
+		if len(key.Key) < minConfigKeyLength {
+			return fmt.Errorf("api key %s must be at least %d characters", name, minConfigKeyLength)
+		}
+		if err := validateScopes(key.Scopes); err != nil {
+			return fmt.Errorf("api key %s: %w", name, err)
+		}
+		hash := hashToken(key.Key)
+		s.apiKeys[hash] = &APIKey{
+			ID:     sessionID(hash),
+			Name:   name,
+			Scopes: key.Scopes,
+			Modems: key.Modems,
+			Config: true,
+		}
+	}
+	return nil
+}
+
+// CreateAPIKey creates an API key and returns it. The key cannot be retrieved
+// again later.
+func (s *Store) CreateAPIKey(name string, scopes []string, modems []string) (string, APIKey, error) {
+	if err := validateScopes(scopes); err != nil {
+		return "", APIKey{}, err
+	}
+	keyBytes := make([]byte, 32)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return "", APIKey{}, fmt.Errorf("generating api key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(keyBytes)
+	hash := hashToken(key)
+	now := time.Now()
+	apiKey := &APIKey{
+		ID:        sessionID(hash),
+		Name:      strings.TrimSpace(name),
+		Scopes:    scopes,
+		Modems:    modems,
+		CreatedAt: now,
+		flushedAt: now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.putAPIKey(hash, apiKey); err != nil {
+		return "", APIKey{}, err
+	}
+	s.apiKeys[hash] = apiKey
+	return key, *apiKey, nil
+}
+
+// ValidateAPIKey returns the API key and records its use.
+func (s *Store) ValidateAPIKey(key string) (APIKey, bool) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return APIKey{}, false
+	}
+	now := time.Now()
+	hash := hashToken(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	apiKey, ok := s.apiKeys[hash]
+	if !ok {
+		return APIKey{}, false
+	}
+	apiKey.LastUsedAt = now
+	if !apiKey.Config && now.Sub(apiKey.flushedAt) >= lastUseFlushInterval {
+		if err := s.putAPIKey(hash, apiKey); err != nil {
+			slog.Warn("failed to persist api key", "key", apiKey.ID, "error", err)
+		} else {
+			apiKey.flushedAt = now
+		}
+	}
+	return *apiKey, true
+}
+
+// APIKeys returns every API key, sorted by name.
+func (s *Store) APIKeys() []APIKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]APIKey, 0, len(s.apiKeys))
+	for _, key := range s.apiKeys {
+		keys = append(keys, *key)
+	}
+	slices.SortFunc(keys, func(a, b APIKey) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+	})
+	return keys
+}
+
+// DeleteAPIKey deletes the API key with the given ID.
+func (s *Store) DeleteAPIKey(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, key := range s.apiKeys {
+		if key.ID != id {
+			continue
+		}
+		if key.Config {
+			return ErrAPIKeyReadOnly
+		}
+		delete(s.apiKeys, hash)
+		if err := s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(apiKeysBucket).Delete([]byte(hash))
+		}); err != nil {
+			return fmt.Errorf("deleting api key: %w", err)
+		}
+		return nil
+	}
+	return ErrAPIKeyNotFound
+}
+
+func (s *Store) loadAPIKeys() error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(apiKeysBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var key APIKey
+			if err := json.Unmarshal(v, &key); err != nil {
+				return fmt.Errorf("decoding api key: %w", err)
+			}
+			key.flushedAt = key.LastUsedAt
+			s.apiKeys[string(k)] = &key
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("loading api keys: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) flushAPIKeys() error {
+	var errs error
+	for hash, key := range s.apiKeys {
+		if key.Config || !key.LastUsedAt.After(key.flushedAt) {
+			continue
+		}
+		if err := s.putAPIKey(hash, key); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		key.flushedAt = key.LastUsedAt
+	}
+	return errs
+}
+
+func (s *Store) putAPIKey(hash string, key *APIKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("encoding api key: %w", err)
+	}
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(apiKeysBucket).Put([]byte(hash), data)
+	}); err != nil {
+		return fmt.Errorf("saving api key: %w", err)
+	}
+	return nil
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+	return nil
+}
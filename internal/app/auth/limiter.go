@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultClientFreeAttempts = 3
+	defaultGlobalFreeAttempts = 10
+	defaultBaseBackoff        = 2 * time.Second
+	defaultMaxBackoff         = 15 * time.Minute
+	defaultAttemptsResetAfter = time.Hour
+)
+
+var ErrTooManyAttempts = errors.New("too many failed attempts, please retry later")
+
+// Limiter throttles OTP verification. Failures are counted per client IP and
+// globally; once a counter exceeds its free attempts every further failure
+// doubles the lockout, up to maxBackoff. Counters are forgotten after a quiet
+// period without failures.
+type Limiter struct {
+	mu                 sync.Mutex
+	clients            map[string]*attempts
+	global             attempts
+	clientFreeAttempts int
+	globalFreeAttempts int
+	baseBackoff        time.Duration
+	maxBackoff         time.Duration
+	resetAfter         time.Duration
+}
+
+type attempts struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// Lockout describes a client or the whole instance getting locked out.
+type Lockout struct {
+	Global   bool
+	Failures int
+	Duration time.Duration
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		clients:            make(map[string]*attempts),
+		clientFreeAttempts: defaultClientFreeAttempts,
+		globalFreeAttempts: defaultGlobalFreeAttempts,
+		baseBackoff:        defaultBaseBackoff,
+		maxBackoff:         defaultMaxBackoff,
+		resetAfter:         defaultAttemptsResetAfter,
+	}
+}
+
+// Allow reports how long the client has to wait before it may attempt a
+// verification. It returns ErrTooManyAttempts while the client or the whole
+// instance is locked out.
+func (l *Limiter) Allow(ip string) (time.Duration, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+	wait := l.global.lockedUntil.Sub(now)
+	if client, ok := l.clients[ip]; ok {
+		wait = max(wait, client.lockedUntil.Sub(now))
+	}
+	if wait > 0 {
+		return wait, ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+// Fail records a failed attempt. It returns the lockouts this failure entered,
+// so callers can notify about each once rather than on every escalation.
+func (l *Limiter) Fail(ip string) []Lockout {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &attempts{}
+		l.clients[ip] = client
+	}
+	var lockouts []Lockout
+	if d := l.record(client, l.clientFreeAttempts, now); client.failures == l.clientFreeAttempts+1 {
+		lockouts = append(lockouts, Lockout{Failures: client.failures, Duration: d})
+	}
+	if d := l.record(&l.global, l.globalFreeAttempts, now); l.global.failures == l.globalFreeAttempts+1 {
+		lockouts = append(lockouts, Lockout{Global: true, Failures: l.global.failures, Duration: d})
+	}
+	return lockouts
+}
+
+// Succeed clears the failures of the client after a successful verification.
+func (l *Limiter) Succeed(ip string) {
+	l.mu.Lock()
+	delete(l.clients, ip)
+	l.mu.Unlock()
+}
+
+func (l *Limiter) record(a *attempts, freeAttempts int, now time.Time) time.Duration {
+	a.failures++
+	a.lastFailure = now
+	if a.failures <= freeAttempts {
+		return 0
+	}
+	backoff := l.maxBackoff
+	if shift := a.failures - freeAttempts - 1; shift < 32 {
+		backoff = min(l.baseBackoff<<shift, l.maxBackoff)
+	}
+	a.lockedUntil = now.Add(backoff)
+	return backoff
+}
+
+func (l *Limiter) prune(now time.Time) {
+	for ip, client := range l.clients {
+		if now.Sub(client.lastFailure) > l.resetAfter && now.After(client.lockedUntil) {
+			delete(l.clients, ip)
+		}
+	}
+	if now.Sub(l.global.lastFailure) > l.resetAfter && now.After(l.global.lockedUntil) {
+		l.global = attempts{}
+	}
+}
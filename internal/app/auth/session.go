@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// lastUseFlushInterval limits how often the last use of a session is written
+// to disk; within the interval it is only updated in memory.
+const lastUseFlushInterval = time.Minute
+
+var sessionsBucket = []byte("sessions")
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is a login. Only the SHA-256 hash of its token is stored, the ID is
+// derived from that hash and is safe to show.
+type Session struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	flushedAt  time.Time
+}
+
+// IssueToken creates a session for the client and returns its token.
+func (s *Store) IssueToken(ip string, userAgent string) (string, Session, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", Session{}, err
+	}
+	now := time.Now()
+	hash := hashToken(token)
+	session := &Session{
+		ID:         sessionID(hash),
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.tokenTTL),
+		IP:         ip,
+		UserAgent:  userAgent,
+		flushedAt:  now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.put(hash, session); err != nil {
+		return "", Session{}, err
+	}
+	s.sessions[hash] = session
+	return token, *session, nil
+}
+
+// ValidateToken returns the session of the token and records its use by the
+// given client.
+func (s *Store) ValidateToken(token string, ip string, userAgent string) (Session, bool) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Session{}, false
+	}
+	now := time.Now()
+	hash := hashToken(token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[hash]
+	if !ok {
+		return Session{}, false
+	}
+	if now.After(session.ExpiresAt) {
+		s.remove(hash)
+		return Session{}, false
+	}
+	session.LastUsedAt = now
+	session.IP = ip
+	session.UserAgent = userAgent
+	if now.Sub(session.flushedAt) >= lastUseFlushInterval {
+		if err := s.put(hash, session); err != nil {
+			slog.Warn("failed to persist session", "session", session.ID, "error", err)
+		} else {
+			session.flushedAt = now
+		}
+	}
+	return *session, true
+}
+
+// Sessions returns the active sessions, most recently used first.
+func (s *Store) Sessions() []Session {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := make([]Session, 0, len(s.sessions))
+	for hash, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			s.remove(hash)
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+	slices.SortFunc(sessions, func(a, b Session) int {
+		return cmp.Or(b.LastUsedAt.Compare(a.LastUsedAt), strings.Compare(a.ID, b.ID))
+	})
+	return sessions
+}
+
+// Revoke ends the session with the given ID.
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, session := range s.sessions {
+		if session.ID == id {
+			return s.remove(hash)
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// flush writes the pending last use of every session and API key to disk.
+func (s *Store) flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errs := s.flushAPIKeys()
+	for hash, session := range s.sessions {
+		if !session.LastUsedAt.After(session.flushedAt) {
+			continue
+		}
+		if err := s.put(hash, session); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		session.flushedAt = session.LastUsedAt
+	}
+	return errs
+}
+
+func (s *Store) load() error {
+	now := time.Now()
+	var expired []string
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(sessionsBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var session Session
+			if err := json.Unmarshal(v, &session); err != nil {
+				return fmt.Errorf("decoding session: %w", err)
+			}
+			if now.After(session.ExpiresAt) {
+				expired = append(expired, string(k))
+				return nil
+			}
+			session.flushedAt = session.LastUsedAt
+			s.sessions[string(k)] = &session
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("loading sessions: %w", err)
+	}
+	for _, hash := range expired {
+		if err := s.remove(hash); err != nil {
+			slog.Warn("failed to remove expired session", "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) put(hash string, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("encoding session: %w", err)
+	}
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(hash), data)
+	}); err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) remove(hash string) error {
+	delete(s.sessions, hash)
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(hash))
+	}); err != nil {
+		return fmt.Errorf("deleting session: %w", err)
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sessionID(hash string) string {
+	return hash[:16]
+}
@@ -5,10 +5,15 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	bolt "go.etcd.io/bbolt"
 )
 
 const (
@@ -17,17 +22,26 @@ const (
 	defaultOTPTTL      = 10 * time.Minute
 	defaultOTPCooldown = 30 * time.Second
 	defaultTokenTTL    = 7 * 24 * time.Hour
+	defaultOTPAttempts = 5
 )
 
-var ErrOTPCooldown = errors.New("otp requested too soon")
+var (
+	ErrOTPCooldown    = errors.New("otp requested too soon")
+	ErrInvalidOTP     = errors.New("invalid otp")
+	ErrOTPInvalidated = errors.New("too many failed attempts, please request a new otp")
+)
 
 type Store struct {
 	mu              sync.Mutex
+	db              *bolt.DB
 	otps            map[string]otpEntry
-	tokens          map[string]tokenEntry
+	sessions        map[string]*Session
+	apiKeys         map[string]*APIKey
 	otpTTL          time.Duration
 	otpCooldown     time.Duration
 	tokenTTL        time.Duration
+	otpAttempts     int
+	otpFailures     int
 	lastOTPIssuedAt time.Time
 }
 
@@ -35,18 +49,42 @@ type otpEntry struct {
 	expiresAt time.Time
 }
 
-type tokenEntry struct {
-	expiresAt time.Time
-}
-
-func NewStore() *Store {
-	return &Store{
+// Open loads the sessions and API keys persisted at path. OTPs are only kept
+// in memory.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating session directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening session store: %w", err)
+	}
+	s := &Store{
+		db:          db,
 		otps:        make(map[string]otpEntry),
-		tokens:      make(map[string]tokenEntry),
+		sessions:    make(map[string]*Session),
+		apiKeys:     make(map[string]*APIKey),
 		otpTTL:      defaultOTPTTL,
 		otpCooldown: defaultOTPCooldown,
 		tokenTTL:    defaultTokenTTL,
+		otpAttempts: defaultOTPAttempts,
+	}
+	if err := s.load(); err != nil {
+		db.Close()
+		return nil, err
 	}
+	if err := s.loadAPIKeys(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) Close() error {
+	if err := s.flush(); err != nil {
+		slog.Warn("failed to persist sessions", "error", err)
+	}
+	return s.db.Close()
 }
 
 func (s *Store) IssueOTP() (string, time.Time, error) {
@@ -66,64 +104,38 @@ func (s *Store) IssueOTP() (string, time.Time, error) {
 	s.otps = map[string]otpEntry{
 		code: {expiresAt: expiresAt},
 	}
+	s.otpFailures = 0
 	s.mu.Unlock()
 
 	return code, expiresAt, nil
 }
 
-func (s *Store) VerifyOTP(code string) bool {
+// VerifyOTP consumes the OTP if code matches it. The OTP is invalidated once
+// it has been guessed wrong too many times, ErrOTPInvalidated is returned for
+// the failure that invalidated it.
+func (s *Store) VerifyOTP(code string) error {
 	code = strings.TrimSpace(code)
-	if code == "" {
-		return false
-	}
 	now := time.Now()
 
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	entry, ok := s.otps[code]
-	if !ok {
-		s.mu.Unlock()
-		return false
+	if !ok || code == "" {
+		if len(s.otps) == 0 {
+			return ErrInvalidOTP
+		}
+		s.otpFailures++
+		if s.otpFailures >= s.otpAttempts {
+			s.otps = make(map[string]otpEntry)
+			return ErrOTPInvalidated
+		}
+		return ErrInvalidOTP
 	}
 	delete(s.otps, code)
-	s.mu.Unlock()
-
-	return now.Before(entry.expiresAt)
-}
-
-func (s *Store) IssueToken() (string, time.Time, error) {
-	token, err := generateToken()
-	if err != nil {
-		return "", time.Time{}, err
-	}
-	expiresAt := time.Now().Add(s.tokenTTL)
-
-	s.mu.Lock()
-	s.tokens[token] = tokenEntry{expiresAt: expiresAt}
-	s.mu.Unlock()
-
-	return token, expiresAt, nil
-}
-
-func (s *Store) ValidateToken(token string) bool {
-	token = strings.TrimSpace(token)
-	if token == "" {
-		return false
+	if !now.Before(entry.expiresAt) {
+		return ErrInvalidOTP
 	}
-	now := time.Now()
-
-	s.mu.Lock()
-	entry, ok := s.tokens[token]
-	if !ok {
-		s.mu.Unlock()
-		return false
-	}
-	if now.After(entry.expiresAt) {
-		delete(s.tokens, token)
-		s.mu.Unlock()
-		return false
-	}
-	s.mu.Unlock()
-	return true
+	return nil
 }
 
 func generateOTP() (string, error) {
@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/damonto/sigmo/internal/pkg/telegram"
+)
+
+const (
+	pollTimeout      = 50 * time.Second
+	maxMessageLength = 4096
+)
+
+type update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+type message struct {
+	MessageID      int64    `json:"message_id"`
+	Chat           chat     `json:"chat"`
+	Text           string   `json:"text"`
+	ReplyToMessage *message `json:"reply_to_message"`
+}
+
+type chat struct {
+	ID int64 `json:"id"`
+}
+
+type getUpdatesRequest struct {
+	Offset         int64    `json:"offset,omitempty"`
+	Timeout        int      `json:"timeout"`
+	AllowedUpdates []string `json:"allowed_updates"`
+}
+
+type sendMessageRequest struct {
+	ChatID          int64            `json:"chat_id"`
+	Text            string           `json:"text"`
+	ReplyParameters *replyParameters `json:"reply_parameters,omitempty"`
+}
+
+type replyParameters struct {
+	MessageID int64 `json:"message_id"`
+}
+
+type api struct {
+	*telegram.Client
+}
+
+func newAPI(endpoint string, botToken string) (*api, error) {
+	client, err := telegram.New(endpoint, botToken)
+	if err != nil {
+		return nil, err
+	}
+	return &api{Client: client}, nil
+}
+
+func (a *api) getUpdates(ctx context.Context, offset int64) ([]update, error) {
+	var updates []update
+	if err := a.Call(ctx, "getUpdates", getUpdatesRequest{
+		Offset:         offset,
+		Timeout:        int(pollTimeout.Seconds()),
+		AllowedUpdates: []string{"message"},
+	}, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+func (a *api) sendMessage(ctx context.Context, chatID int64, replyTo int64, text string) error {
+	if runes := []rune(text); len(runes) > maxMessageLength {
+		text = string(runes[:maxMessageLength-1]) + "…"
+	}
+	req := sendMessageRequest{ChatID: chatID, Text: text}
+	if replyTo != 0 {
+		req.ReplyParameters = &replyParameters{MessageID: replyTo}
+	}
+	return a.Call(ctx, "sendMessage", req, nil)
+}
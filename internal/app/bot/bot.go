@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/damonto/sigmo/internal/app/archive"
+	"github.com/damonto/sigmo/internal/app/events"
+	"github.com/damonto/sigmo/internal/app/handler/esim"
+	hmessage "github.com/damonto/sigmo/internal/app/handler/message"
+	hmodem "github.com/damonto/sigmo/internal/app/handler/modem"
+	"github.com/damonto/sigmo/internal/app/handler/ussd"
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+	"github.com/damonto/sigmo/internal/pkg/notify"
+)
+
+const retryInterval = 5 * time.Second
+
+// Bot answers Telegram messages from authorised chats. A reply to a forwarded
+// SMS is sent back to its sender from the same modem, everything else is
+// treated as a command.
+type Bot struct {
+	cfg      *config.Config
+	manager  *modem.Manager
+	pollers  []*poller
+	modems   *hmodem.Service
+	messages *hmessage.Service
+	ussd     *ussd.Service
+	esims    *esim.Service
+	replies  *notify.TelegramReplies
+}
+
+// poller long-polls a single bot. Channels sharing a bot token share a poller,
+// as Telegram only allows one getUpdates consumer per bot.
+type poller struct {
+	api   *api
+	chats []int64
+}
+
+func New(cfg *config.Config, manager *modem.Manager, store *archive.Store, hub *events.Hub, replies *notify.TelegramReplies) (*Bot, error) {
+	b := &Bot{
+		cfg:      cfg,
+		manager:  manager,
+		modems:   hmodem.NewService(cfg, manager),
+		messages: hmessage.NewService(store, hub),
+		ussd:     ussd.NewService(),
+		esims:    esim.NewService(cfg, manager, hub),
+		replies:  replies,
+	}
+	pollers := make(map[string]*poller)
+	names := make([]string, 0, len(cfg.Channels))
+	for name := range cfg.Channels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		channel := cfg.Channels[name]
+		if !channel.Interactive || cfg.ChannelType(name) != "telegram" {
+			continue
+		}
+		if strings.TrimSpace(channel.BotToken) == "" {
+			return nil, fmt.Errorf("channel %s: telegram bot token is required", name)
+		}
+		chats, err := channel.Recipients.Int64s()
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: parsing telegram recipients: %w", name, err)
+		}
+		key := strings.TrimSpace(channel.Endpoint) + "\x00" + channel.BotToken
+		p, ok := pollers[key]
+		if !ok {
+			client, err := newAPI(channel.Endpoint, channel.BotToken)
+			if err != nil {
+				return nil, fmt.Errorf("channel %s: %w", name, err)
+			}
+			p = &poller{api: client}
+			pollers[key] = p
+			b.pollers = append(b.pollers, p)
+		}
+		for _, chat := range chats {
+			if !slices.Contains(p.chats, chat) {
+				p.chats = append(p.chats, chat)
+			}
+		}
+	}
+	return b, nil
+}
+
+func (b *Bot) Enabled() bool {
+	return len(b.pollers) > 0
+}
+
+func (b *Bot) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, p := range b.pollers {
+		wg.Go(func() {
+			b.poll(ctx, p)
+		})
+	}
+	wg.Wait()
+	return nil
+}
+
+func (b *Bot) poll(ctx context.Context, p *poller) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := p.api.getUpdates(ctx, offset)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			slog.Warn("failed to fetch telegram updates", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = max(offset, u.UpdateID+1)
+			if u.Message == nil || strings.TrimSpace(u.Message.Text) == "" {
+				continue
+			}
+			if !slices.Contains(p.chats, u.Message.Chat.ID) {
+				slog.Warn("ignoring telegram message from unauthorised chat", "chat", u.Message.Chat.ID)
+				continue
+			}
+			b.handle(ctx, p, u.Message)
+		}
+	}
+}
+
+func (b *Bot) handle(ctx context.Context, p *poller, msg *message) {
+	var reply string
+	if msg.ReplyToMessage != nil && !strings.HasPrefix(msg.Text, "/") {
+		reply = b.answer(p, msg)
+	} else {
+		reply = b.command(ctx, msg.Text)
+	}
+	if err := p.api.sendMessage(ctx, msg.Chat.ID, msg.MessageID, reply); err != nil && ctx.Err() == nil {
+		slog.Error("failed to send telegram reply", "chat", msg.Chat.ID, "error", err)
+	}
+}
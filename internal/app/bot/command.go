@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+const ussdTimeout = time.Minute
+
+const helpText = `Reply to a forwarded SMS to answer it from the same modem.
+
+/modems - list modems
+/send <modem> <number> <text> - send an SMS
+/ussd <modem> <code> - run a USSD code or answer an open session
+/esims <modem> - list eSIM profiles
+
+<modem> is the number shown by /modems, the modem alias or its ID.`
+
+var errModemRequired = errors.New("modem is required")
+
+func (b *Bot) answer(p *poller, msg *message) string {
+	sms, ok := b.replies.Get(p.api.BotToken(), msg.Chat.ID, msg.ReplyToMessage.MessageID)
+	if !ok {
+		return "This message is not a forwarded SMS, or it was forwarded before the last restart."
+	}
+	m, err := b.manager.Find(sms.ModemID)
+	if err != nil {
+		return fmt.Sprintf("Modem %s is not available.", sms.Modem)
+	}
+	to := sms.Participant()
+	if _, err := b.messages.Send(m, to, msg.Text); err != nil {
+		return fmt.Sprintf("Failed to send SMS to %s: %v", to, err)
+	}
+	return fmt.Sprintf("SMS sent to %s.", to)
+}
+
+func (b *Bot) command(ctx context.Context, text string) string {
+	name, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	// Commands in groups may be addressed to the bot, e.g. /modems@sigmo_bot.
+	name, _, _ = strings.Cut(strings.ToLower(name), "@")
+	args = strings.TrimSpace(args)
+	switch name {
+	case "/modems":
+		return b.listModems()
+	case "/send":
+		return b.send(args)
+	case "/ussd":
+		return b.runUSSD(ctx, args)
+	case "/esims":
+		return b.listEsims(args)
+	default:
+		return helpText
+	}
+}
+
+func (b *Bot) listModems() string {
+	modems, err := b.modems.List()
+	if err != nil {
+		return fmt.Sprintf("Failed to list modems: %v", err)
+	}
+	if len(modems) == 0 {
+		return "No modems found."
+	}
+	var sb strings.Builder
+	for i, m := range modems {
+		fmt.Fprintf(&sb, "%d. %s (%s)\n", i+1, m.Name, m.ID)
+		if m.Number != "" {
+			fmt.Fprintf(&sb, "   Number: %s\n", m.Number)
+		}
+		fmt.Fprintf(&sb, "   Operator: %s, %s, %s, signal %d%%\n", m.RegisteredOperator.Name, m.AccessTechnology, m.RegistrationState, m.SignalQuality)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func (b *Bot) send(args string) string {
+	ref, rest, _ := strings.Cut(args, " ")
+	to, text, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	if ref == "" || to == "" || strings.TrimSpace(text) == "" {
+		return "Usage: /send <modem> <number> <text>"
+	}
+	m, err := b.findModem(ref)
+	if err != nil {
+		return err.Error()
+	}
+	if _, err := b.messages.Send(m, to, strings.TrimSpace(text)); err != nil {
+		return fmt.Sprintf("Failed to send SMS to %s: %v", to, err)
+	}
+	return fmt.Sprintf("SMS sent to %s.", to)
+}
+
+func (b *Bot) runUSSD(ctx context.Context, args string) string {
+	ref, code, _ := strings.Cut(args, " ")
+	code = strings.TrimSpace(code)
+	if ref == "" || code == "" {
+		return "Usage: /ussd <modem> <code>"
+	}
+	m, err := b.findModem(ref)
+	if err != nil {
+		return err.Error()
+	}
+	state, err := m.ThreeGPP().USSD().State()
+	if err != nil {
+		slog.Error("failed to read ussd state", "modem", m.EquipmentIdentifier, "error", err)
+		return fmt.Sprintf("Failed to read USSD state: %v", err)
+	}
+	action := "initialize"
+	if state == modem.Modem3gppUssdSessionStateUserResponse {
+		action = "reply"
+	}
+	ctx, cancel := context.WithTimeout(ctx, ussdTimeout)
+	defer cancel()
+	response, err := b.ussd.Execute(ctx, m, action, code)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "USSD request timed out, please retry."
+		}
+		return fmt.Sprintf("USSD request failed: %v", err)
+	}
+	if strings.TrimSpace(response.Reply) == "" {
+		return "USSD request sent, no reply received."
+	}
+	return response.Reply
+}
+
+func (b *Bot) listEsims(args string) string {
+	if args == "" {
+		return "Usage: /esims <modem>"
+	}
+	m, err := b.findModem(args)
+	if err != nil {
+		return err.Error()
+	}
+	profiles, err := b.esims.List(m)
+	if err != nil {
+		return fmt.Sprintf("Failed to list eSIM profiles: %v", err)
+	}
+	if len(profiles) == 0 {
+		return "No eSIM profiles installed."
+	}
+	var sb strings.Builder
+	for _, profile := range profiles {
+		state := "disabled"
+		if profile.ProfileState == 1 {
+			state = "enabled"
+		}
+		fmt.Fprintf(&sb, "%s (%s)\n   ICCID: %s\n", profile.Name, state, profile.ICCID)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// findModem resolves a modem by its position in /modems, its alias or its
+// equipment identifier.
+func (b *Bot) findModem(ref string) (*modem.Modem, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errModemRequired
+	}
+	if m, err := b.manager.Find(ref); err == nil {
+		return m, nil
+	}
+	modems, err := b.manager.Modems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modems: %w", err)
+	}
+	ids := make([]string, 0, len(modems))
+	byID := make(map[string]*modem.Modem, len(modems))
+	for _, m := range modems {
+		ids = append(ids, m.EquipmentIdentifier)
+		byID[m.EquipmentIdentifier] = m
+	}
+	slices.Sort(ids)
+	if index, err := strconv.Atoi(ref); err == nil && index >= 1 && index <= len(ids) {
+		return byID[ids[index-1]], nil
+	}
+	for _, id := range ids {
+		if strings.EqualFold(strings.TrimSpace(b.cfg.FindModem(id).Alias), ref) {
+			return byID[id], nil
+		}
+	}
+	return nil, fmt.Errorf("modem %s not found", ref)
+}
@@ -0,0 +1,143 @@
+package dispatcher
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	sgp22 "github.com/damonto/euicc-go/v2"
+	bolt "go.etcd.io/bbolt"
+)
+
+var attemptsBucket = []byte("attempts")
+
+// Attempt is the delivery state of a notification that could not be sent
+// yet.
+type Attempt struct {
+	EID           string               `json:"eid"`
+	Sequence      sgp22.SequenceNumber `json:"sequence"`
+	ICCID         string               `json:"iccid"`
+	Operation     string               `json:"operation"`
+	SMDP          string               `json:"smdp"`
+	Attempts      int                  `json:"attempts"`
+	LastError     string               `json:"lastError"`
+	LastAttemptAt time.Time            `json:"lastAttemptAt"`
+	NextAttemptAt time.Time            `json:"nextAttemptAt"`
+	// Failed is set once the notification is given up on; it is only sent
+	// again on request.
+	Failed bool `json:"failed"`
+	// Sent is set when the notification was delivered but could not be
+	// removed from the eUICC.
+	Sent bool `json:"sent"`
+}
+
+// attempts keeps the delivery state of failing notifications, in a bucket
+// per EID keyed by sequence number, so that the backoff survives a restart.
+type attempts struct {
+	db *bolt.DB
+}
+
+func openAttempts(path string) (*attempts, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening notification attempts: %w", err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(attemptsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing notification attempts: %w", err)
+	}
+	return &attempts{db: db}, nil
+}
+
+func (a *attempts) Close() error {
+	return a.db.Close()
+}
+
+func sequenceKey(sequence sgp22.SequenceNumber) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(sequence))
+	return key
+}
+
+func (a *attempts) get(eid string, sequence sgp22.SequenceNumber) (Attempt, bool, error) {
+	var (
+		attempt Attempt
+		found   bool
+	)
+	err := a.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(attemptsBucket).Bucket([]byte(eid))
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get(sequenceKey(sequence))
+		if data == nil {
+			return nil
+		}
+		found = true
+		if err := json.Unmarshal(data, &attempt); err != nil {
+			return fmt.Errorf("decoding notification attempt: %w", err)
+		}
+		return nil
+	})
+	return attempt, found, err
+}
+
+func (a *attempts) record(attempt Attempt) error {
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return fmt.Errorf("encoding notification attempt: %w", err)
+	}
+	return a.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(attemptsBucket).CreateBucketIfNotExists([]byte(attempt.EID))
+		if err != nil {
+			return err
+		}
+		return bucket.Put(sequenceKey(attempt.Sequence), data)
+	})
+}
+
+func (a *attempts) remove(eid string, sequence sgp22.SequenceNumber) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(attemptsBucket).Bucket([]byte(eid))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete(sequenceKey(sequence))
+	})
+}
+
+// prune drops the state of the notifications of the eUICC that are no longer
+// pending, e.g. because they were removed by hand.
+func (a *attempts) prune(eid string, pending []sgp22.SequenceNumber) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(attemptsBucket).Bucket([]byte(eid))
+		if bucket == nil {
+			return nil
+		}
+		var stale [][]byte
+		if err := bucket.ForEach(func(k, _ []byte) error {
+			if !slices.Contains(pending, sgp22.SequenceNumber(binary.BigEndian.Uint64(k))) {
+				stale = append(stale, k)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, k := range stale {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,319 @@
+package dispatcher
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	sgp22 "github.com/damonto/euicc-go/v2"
+
+	"github.com/damonto/sigmo/internal/app/events"
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/lpa"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+const (
+	defaultInterval = 5 * time.Minute
+	defaultAttempts = 10
+	minInterval     = 30 * time.Second
+	maxBackoff      = 24 * time.Hour
+	// addedDelay holds back the first run after a modem comes back, e.g.
+	// after a profile switch, which sends its own notifications first.
+	addedDelay = time.Minute
+)
+
+const (
+	statusSent     = "sent"
+	statusRetrying = "retrying"
+	statusFailed   = "failed"
+)
+
+// Dispatcher sends the pending notifications of every eUICC to their SM-DP+
+// in the background and removes them once delivered. A notification that
+// fails is retried with exponential backoff and given up on after a number
+// of attempts.
+type Dispatcher struct {
+	cfg         *config.Config
+	manager     *modem.Manager
+	hub         *events.Hub
+	interval    time.Duration
+	maxAttempts int
+	attempts    *attempts
+	mu          sync.Mutex
+	cancels     map[string]context.CancelFunc
+}
+
+func New(cfg *config.Config, manager *modem.Manager, hub *events.Hub) (*Dispatcher, error) {
+	d := &Dispatcher{
+		cfg:         cfg,
+		manager:     manager,
+		hub:         hub,
+		interval:    cfg.Esim.NotificationInterval,
+		maxAttempts: cfg.Esim.NotificationAttempts,
+		cancels:     make(map[string]context.CancelFunc),
+	}
+	if d.interval <= 0 {
+		d.interval = defaultInterval
+	}
+	d.interval = max(d.interval, minInterval)
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultAttempts
+	}
+	var err error
+	if d.attempts, err = openAttempts(cfg.DataPath("notifications.db")); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Dispatcher) Run(ctx context.Context) error {
+	modems, err := d.manager.Modems()
+	if err != nil {
+		return fmt.Errorf("listing modems: %w", err)
+	}
+	for _, m := range modems {
+		d.start(ctx, m.EquipmentIdentifier, 0)
+	}
+	unsubscribe, err := d.manager.Subscribe(func(event modem.ModemEvent) error {
+		if event.Modem == nil {
+			return nil
+		}
+		switch event.Type {
+		case modem.ModemEventAdded:
+			d.start(ctx, event.Modem.EquipmentIdentifier, addedDelay)
+		case modem.ModemEventRemoved:
+			d.stop(event.Modem.EquipmentIdentifier)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("subscribing to modem manager: %w", err)
+	}
+	defer unsubscribe()
+
+	<-ctx.Done()
+	d.mu.Lock()
+	for id, cancel := range d.cancels {
+		cancel()
+		delete(d.cancels, id)
+	}
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *Dispatcher) Close() error {
+	return d.attempts.Close()
+}
+
+// Status returns the delivery state of a pending notification that failed
+// at least once.
+func (d *Dispatcher) Status(eid string, sequence sgp22.SequenceNumber) (Attempt, bool, error) {
+	return d.attempts.get(eid, sequence)
+}
+
+// MarkSent records a notification that was delivered by hand, so that the
+// next run only removes it from the eUICC instead of sending it again.
+func (d *Dispatcher) MarkSent(eid string, sequence sgp22.SequenceNumber) error {
+	attempt, ok, err := d.attempts.get(eid, sequence)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		attempt = Attempt{EID: eid, Sequence: sequence}
+	}
+	attempt.Sent = true
+	attempt.Failed = false
+	attempt.NextAttemptAt = time.Time{}
+	return d.attempts.record(attempt)
+}
+
+// start runs the worker of the modem after delay, restarting it when the
+// modem comes back, e.g. after a profile switch, so that new notifications go
+// out soon.
+func (d *Dispatcher) start(ctx context.Context, id string, delay time.Duration) {
+	if ctx.Err() != nil {
+		return
+	}
+	d.mu.Lock()
+	if cancel, ok := d.cancels[id]; ok {
+		cancel()
+	}
+	workerCtx, cancel := context.WithCancel(ctx)
+	d.cancels[id] = cancel
+	d.mu.Unlock()
+	go d.work(workerCtx, id, delay)
+}
+
+func (d *Dispatcher) stop(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if cancel, ok := d.cancels[id]; ok {
+		cancel()
+		delete(d.cancels, id)
+	}
+}
+
+func (d *Dispatcher) work(ctx context.Context, id string, delay time.Duration) {
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		if err := d.dispatch(id); err != nil {
+			if errors.Is(err, lpa.ErrNoSupportedAID) {
+				slog.Info("not an eUICC, not sending notifications", "modem", id)
+				return
+			}
+			slog.Warn("failed to send pending notifications", "modem", id, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(id string) error {
+	m, err := d.manager.Find(id)
+	if err != nil {
+		return err
+	}
+	client, err := lpa.New(m, d.cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := client.Close(); cerr != nil {
+			slog.Warn("failed to close LPA client", "error", cerr)
+		}
+	}()
+
+	raw, err := client.EID()
+	if err != nil {
+		return fmt.Errorf("reading EID: %w", err)
+	}
+	eid := hex.EncodeToString(raw)
+	notifications, err := client.ListNotification()
+	if err != nil {
+		return fmt.Errorf("listing notifications: %w", err)
+	}
+	pending := make([]sgp22.SequenceNumber, 0, len(notifications))
+	for _, n := range notifications {
+		pending = append(pending, n.SequenceNumber)
+	}
+	if err := d.attempts.prune(eid, pending); err != nil {
+		slog.Error("failed to prune notification attempts", "modem", id, "error", err)
+	}
+
+	now := time.Now()
+	for _, n := range notifications {
+		attempt, ok, err := d.attempts.get(eid, n.SequenceNumber)
+		if err != nil {
+			slog.Error("failed to read notification attempts", "modem", id, "sequence", n.SequenceNumber, "error", err)
+			continue
+		}
+		if ok && attempt.Sent {
+			d.removeSent(client, id, attempt)
+			continue
+		}
+		if ok && (attempt.Failed || now.Before(attempt.NextAttemptAt)) {
+			continue
+		}
+		if !ok {
+			attempt = Attempt{
+				EID:       eid,
+				Sequence:  n.SequenceNumber,
+				ICCID:     n.ICCID.String(),
+				Operation: lpa.NotificationOperation(n.ProfileManagementOperation),
+				SMDP:      n.Address,
+			}
+		}
+		if err := client.SendNotification(n.SequenceNumber, false); err != nil {
+			d.retry(id, attempt, now, err)
+			continue
+		}
+		slog.Info("notification sent", "modem", id, "sequence", n.SequenceNumber, "operation", attempt.Operation, "smdp", attempt.SMDP)
+		attempt.LastError = ""
+		d.publish(id, attempt, statusSent)
+		d.removeSent(client, id, attempt)
+	}
+	return nil
+}
+
+// removeSent removes a delivered notification from the eUICC. If that fails
+// the notification is marked sent, so that the next run only removes it
+// instead of sending it again.
+func (d *Dispatcher) removeSent(client *lpa.LPA, id string, attempt Attempt) {
+	if err := client.RemoveNotificationFromList(attempt.Sequence); err != nil {
+		slog.Warn("failed to remove sent notification", "modem", id, "sequence", attempt.Sequence, "error", err)
+		attempt.Sent = true
+		if err := d.attempts.record(attempt); err != nil {
+			slog.Error("failed to record notification attempt", "modem", id, "sequence", attempt.Sequence, "error", err)
+		}
+		return
+	}
+	if err := d.attempts.remove(attempt.EID, attempt.Sequence); err != nil {
+		slog.Error("failed to clear notification attempts", "modem", id, "sequence", attempt.Sequence, "error", err)
+	}
+}
+
+// retry records a failed attempt and schedules the next one, doubling the
+// delay every time, or gives up after the last attempt.
+func (d *Dispatcher) retry(id string, attempt Attempt, now time.Time, cause error) {
+	attempt.Attempts++
+	attempt.LastError = cause.Error()
+	attempt.LastAttemptAt = now
+	status := statusRetrying
+	if attempt.Attempts >= d.maxAttempts {
+		attempt.Failed = true
+		attempt.NextAttemptAt = time.Time{}
+		status = statusFailed
+		slog.Error("giving up on notification", "modem", id, "sequence", attempt.Sequence, "operation", attempt.Operation, "smdp", attempt.SMDP, "attempts", attempt.Attempts, "error", cause)
+	} else {
+		attempt.NextAttemptAt = now.Add(backoff(d.interval, attempt.Attempts))
+		slog.Warn("failed to send notification", "modem", id, "sequence", attempt.Sequence, "operation", attempt.Operation, "smdp", attempt.SMDP, "attempts", attempt.Attempts, "retry", attempt.NextAttemptAt, "error", cause)
+	}
+	if err := d.attempts.record(attempt); err != nil {
+		slog.Error("failed to record notification attempt", "modem", id, "sequence", attempt.Sequence, "error", err)
+	}
+	d.publish(id, attempt, status)
+}
+
+func (d *Dispatcher) publish(id string, attempt Attempt, status string) {
+	d.hub.Publish(events.TypeEsimNotification, id, events.EsimNotificationData{
+		Sequence:      uint64(attempt.Sequence),
+		ICCID:         attempt.ICCID,
+		Operation:     attempt.Operation,
+		SMDP:          attempt.SMDP,
+		Status:        status,
+		Attempts:      attempt.Attempts,
+		NextAttemptAt: attempt.NextAttemptAt,
+		Error:         attempt.LastError,
+	})
+}
+
+// backoff returns the delay before the next attempt: the interval after the
+// first failure, doubled after every further one, up to a day.
+func backoff(interval time.Duration, attempts int) time.Duration {
+	delay := interval
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const subscriberBuffer = 64
+
+const (
+	TypeModemAdded        = "modem.added"
+	TypeModemRemoved      = "modem.removed"
+	TypeModemState        = "modem.state"
+	TypeModemRegistration = "modem.registration"
+	TypeModemSignal       = "modem.signal"
+	TypeSMSReceived       = "sms.received"
+	TypeSMSSent           = "sms.sent"
+	TypeSMSFailed         = "sms.failed"
+	TypeSMSStatus         = "sms.status"
+	TypeUSSDNotification  = "ussd.notification"
+	TypeUSSDRequest       = "ussd.request"
+	TypeEsimProgress      = "esim.progress"
+	TypeEsimNotification  = "esim.notification"
+	TypeCallAdded         = "call.added"
+)
+
+// Event is a typed notification pushed to event stream clients.
+type Event struct {
+	Type  string    `json:"type"`
+	Modem string    `json:"modem,omitempty"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data,omitempty"`
+}
+
+// Category returns the part of the type before the dot, e.g. "sms".
+func (e Event) Category() string {
+	category, _, _ := strings.Cut(e.Type, ".")
+	return category
+}
+
+// Hub fans events out to every subscriber. Publishing never blocks: a
+// subscriber that does not keep up loses events rather than stalling the
+// modem signal handling that produces them.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[uint64]chan Event
+	nextID uint64
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[uint64]chan Event)}
+}
+
+func (h *Hub) Publish(eventType string, modemID string, data any) {
+	event := Event{Type: eventType, Modem: modemID, Time: time.Now(), Data: data}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+			slog.Warn("dropping event for slow subscriber", "subscriber", id, "type", eventType)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every published event and a function
+// that cancels the subscription and closes the channel.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	h.nextID++
+	id := h.nextID
+	h.subs[id] = ch
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type ModemData struct {
+	Name         string `json:"name"`
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+	Number       string `json:"number,omitempty"`
+}
+
+type StateData struct {
+	State string `json:"state"`
+}
+
+type SignalData struct {
+	Quality uint32 `json:"quality"`
+	Recent  bool   `json:"recent"`
+}
+
+type SMSData struct {
+	ID        uint64    `json:"id,omitempty"`
+	Number    string    `json:"number"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+	Incoming  bool      `json:"incoming"`
+	Status    string    `json:"status,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type USSDData struct {
+	Message string `json:"message"`
+}
+
+type CallData struct {
+	ID        string `json:"id"`
+	Number    string `json:"number"`
+	Direction string `json:"direction"`
+	State     string `json:"state"`
+}
+
+type EsimProgressData struct {
+	Operation string `json:"operation"`
+	ICCID     string `json:"iccid,omitempty"`
+	Stage     string `json:"stage"`
+	APN       string `json:"apn,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// EsimNotificationData reports an attempt to send an eUICC notification to
+// its SM-DP+. Status is sent, retrying or failed, the latter once it is given
+// up on.
+type EsimNotificationData struct {
+	Sequence      uint64    `json:"sequence"`
+	ICCID         string    `json:"iccid,omitempty"`
+	Operation     string    `json:"operation"`
+	SMDP          string    `json:"smdp"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitzero"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Watcher turns ModemManager signals and modem calls into events. Messages
+// are published by archive.Ingester, which already subscribes to them.
+type Watcher struct {
+	cfg     *config.Config
+	hub     *Hub
+	manager *modem.Manager
+	mu      sync.Mutex
+	cancels map[dbus.ObjectPath]context.CancelFunc
+}
+
+func NewWatcher(cfg *config.Config, hub *Hub, manager *modem.Manager) *Watcher {
+	return &Watcher{
+		cfg:     cfg,
+		hub:     hub,
+		manager: manager,
+		cancels: make(map[dbus.ObjectPath]context.CancelFunc),
+	}
+}
+
+func (w *Watcher) Run(ctx context.Context) error {
+	modems, err := w.manager.Modems()
+	if err != nil {
+		return fmt.Errorf("listing modems: %w", err)
+	}
+	for path, m := range modems {
+		w.watchModem(ctx, path, m)
+	}
+
+	unsubscribe, err := w.manager.Subscribe(func(event modem.ModemEvent) error {
+		switch event.Type {
+		case modem.ModemEventAdded:
+			if event.Modem == nil {
+				return nil
+			}
+			w.hub.Publish(TypeModemAdded, event.Modem.EquipmentIdentifier, w.modemData(event.Modem))
+			w.watchModem(ctx, event.Path, event.Modem)
+		case modem.ModemEventRemoved:
+			w.stopModem(event.Path)
+			if event.Modem != nil {
+				w.hub.Publish(TypeModemRemoved, event.Modem.EquipmentIdentifier, nil)
+			}
+		case modem.ModemEventUpdated:
+			if event.Modem != nil {
+				w.publishChanges(event.Modem, event.Interface, event.Changed)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("subscribing to modem manager: %w", err)
+	}
+	defer unsubscribe()
+
+	<-ctx.Done()
+	w.mu.Lock()
+	for path, cancel := range w.cancels {
+		cancel()
+		delete(w.cancels, path)
+	}
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *Watcher) publishChanges(m *modem.Modem, iface string, changed map[string]dbus.Variant) {
+	id := m.EquipmentIdentifier
+	switch iface {
+	case modem.ModemInterface:
+		if _, ok := changed["State"]; ok {
+			w.hub.Publish(TypeModemState, id, StateData{State: m.State.String()})
+		}
+		if variant, ok := changed["SignalQuality"]; ok {
+			if values, ok := variant.Value().([]any); ok && len(values) == 2 {
+				quality, _ := values[0].(uint32)
+				recent, _ := values[1].(bool)
+				w.hub.Publish(TypeModemSignal, id, SignalData{Quality: quality, Recent: recent})
+			}
+		}
+	case modem.Modem3GPPInterface:
+		if variant, ok := changed["RegistrationState"]; ok {
+			if state, ok := variant.Value().(uint32); ok {
+				w.hub.Publish(TypeModemRegistration, id, StateData{State: modem.Modem3gppRegistrationState(state).String()})
+			}
+		}
+	case modem.Modem3GPPUSSDInterface:
+		if variant, ok := changed["NetworkNotification"]; ok {
+			if message, _ := variant.Value().(string); message != "" {
+				w.hub.Publish(TypeUSSDNotification, id, USSDData{Message: message})
+			}
+		}
+		if variant, ok := changed["NetworkRequest"]; ok {
+			if message, _ := variant.Value().(string); message != "" {
+				w.hub.Publish(TypeUSSDRequest, id, USSDData{Message: message})
+			}
+		}
+	}
+}
+
+func (w *Watcher) watchModem(ctx context.Context, path dbus.ObjectPath, m *modem.Modem) {
+	if ctx.Err() != nil {
+		return
+	}
+	w.mu.Lock()
+	if cancel, ok := w.cancels[path]; ok {
+		cancel()
+	}
+	modemCtx, cancel := context.WithCancel(ctx)
+	w.cancels[path] = cancel
+	w.mu.Unlock()
+
+	go func() {
+		if err := m.Voice().Subscribe(modemCtx, func(call *modem.Call) error {
+			w.hub.Publish(TypeCallAdded, m.EquipmentIdentifier, CallData{
+				ID:        call.ID(),
+				Number:    strings.TrimSpace(call.Number),
+				Direction: call.Direction.String(),
+				State:     call.State.String(),
+			})
+			return nil
+		}); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("modem call event subscription stopped", "error", err, "modem", m.EquipmentIdentifier)
+		}
+	}()
+}
+
+func (w *Watcher) stopModem(path dbus.ObjectPath) {
+	w.mu.Lock()
+	cancel := w.cancels[path]
+	delete(w.cancels, path)
+	w.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (w *Watcher) modemData(m *modem.Modem) ModemData {
+	name := w.cfg.FindModem(m.EquipmentIdentifier).Alias
+	if name == "" {
+		name = m.Model
+	}
+	return ModemData{
+		Name:         name,
+		Manufacturer: m.Manufacturer,
+		Model:        m.Model,
+		Number:       m.Number,
+	}
+}
@@ -0,0 +1,108 @@
+package forwarder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+	"github.com/damonto/sigmo/internal/pkg/notify"
+)
+
+// callRoute is the compiled form of config.Calls.
+type callRoute struct {
+	events   []string
+	channels []string
+	template *template.Template
+}
+
+var defaultCallEvents = []string{notify.CallEventIncoming, notify.CallEventMissed}
+
+func compileCalls(cfg *config.Config, templates map[string]*template.Template) (callRoute, error) {
+	route := callRoute{channels: cfg.Calls.Channels}
+	for _, event := range cfg.Calls.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		switch event {
+		case notify.CallEventIncoming, notify.CallEventMissed, notify.CallEventAnswered:
+			route.events = append(route.events, event)
+		default:
+			return callRoute{}, fmt.Errorf("events must be %s, %s or %s", notify.CallEventIncoming, notify.CallEventMissed, notify.CallEventAnswered)
+		}
+	}
+	if len(route.events) == 0 {
+		route.events = defaultCallEvents
+	}
+	for _, channel := range cfg.Calls.Channels {
+		if _, ok := cfg.Channels[channel]; !ok {
+			return callRoute{}, fmt.Errorf("unknown channel %q", channel)
+		}
+	}
+	if cfg.Calls.Template != "" {
+		tmpl, ok := templates[cfg.Calls.Template]
+		if !ok {
+			return callRoute{}, fmt.Errorf("unknown template %q", cfg.Calls.Template)
+		}
+		route.template = tmpl
+	}
+	return route, nil
+}
+
+// trackCall notifies an incoming call when it rings and again once it ends,
+// as missed or answered with its duration.
+func (r *Relay) trackCall(ctx context.Context, m *modem.Modem, call *modem.Call) {
+	message := notify.CallMessage{
+		ModemID: m.EquipmentIdentifier,
+		Modem:   r.modemName(m),
+		From:    strings.TrimSpace(call.Number),
+		To:      m.Number,
+		Time:    time.Now(),
+		Event:   notify.CallEventIncoming,
+	}
+	if err := r.forwardCall(message); err != nil {
+		slog.Error("failed to forward call", "modem", m.EquipmentIdentifier, "from", message.From, "error", err)
+	}
+
+	var answeredAt time.Time
+	if err := m.Voice().WatchCall(ctx, call, func(state modem.CallState, reason modem.CallStateReason) {
+		if state == modem.CallStateActive && answeredAt.IsZero() {
+			answeredAt = time.Now()
+		}
+	}); err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.Error("failed to watch call", "modem", m.EquipmentIdentifier, "from", message.From, "error", err)
+		}
+		return
+	}
+
+	message.Event = notify.CallEventMissed
+	if !answeredAt.IsZero() {
+		message.Event = notify.CallEventAnswered
+		message.Answered = true
+		message.Duration = time.Since(answeredAt).Round(time.Second)
+	}
+	if err := r.forwardCall(message); err != nil {
+		slog.Error("failed to forward call", "modem", m.EquipmentIdentifier, "from", message.From, "error", err)
+	}
+}
+
+func (r *Relay) forwardCall(message notify.CallMessage) error {
+	if !slices.Contains(r.calls.events, message.Event) {
+		return nil
+	}
+	var out notify.Message = message
+	if r.calls.template != nil {
+		text, err := render(r.calls.template, message)
+		if err != nil {
+			slog.Warn("failed to render call template", "error", err)
+		} else {
+			out = notify.FormattedMessage{Message: message, Text: text}
+		}
+	}
+	return r.notifier.Send(out, r.calls.channels...)
+}
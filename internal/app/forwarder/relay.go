@@ -7,10 +7,12 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 
 	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/metrics"
 	"github.com/damonto/sigmo/internal/pkg/modem"
 	"github.com/damonto/sigmo/internal/pkg/notify"
 )
@@ -19,21 +21,37 @@ type Relay struct {
 	cfg       *config.Config
 	manager   *modem.Manager
 	notifier  *notify.Notifier
+	rules     []rule
+	calls     callRoute
 	mu        sync.Mutex
 	cancels   map[dbus.ObjectPath]context.CancelFunc
 	equipment map[string]dbus.ObjectPath
 	modems    map[dbus.ObjectPath]string
 }
 
-func New(cfg *config.Config, manager *modem.Manager) (*Relay, error) {
-	notifier, err := notify.New(cfg)
+func New(cfg *config.Config, manager *modem.Manager, replies *notify.TelegramReplies) (*Relay, error) {
+	notifier, err := notify.New(cfg, replies)
 	if err != nil {
 		return nil, fmt.Errorf("creating notifier: %w", err)
 	}
+	templates, err := compileTemplates(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("compiling templates: %w", err)
+	}
+	rules, err := compileRules(cfg, templates)
+	if err != nil {
+		return nil, fmt.Errorf("compiling forwarding rules: %w", err)
+	}
+	calls, err := compileCalls(cfg, templates)
+	if err != nil {
+		return nil, fmt.Errorf("compiling call notifications: %w", err)
+	}
 	return &Relay{
 		cfg:       cfg,
 		manager:   manager,
 		notifier:  notifier,
+		rules:     rules,
+		calls:     calls,
 		cancels:   make(map[dbus.ObjectPath]context.CancelFunc),
 		equipment: make(map[string]dbus.ObjectPath),
 		modems:    make(map[dbus.ObjectPath]string),
@@ -116,6 +134,18 @@ func (r *Relay) addModem(ctx context.Context, path dbus.ObjectPath, m *modem.Mod
 		}
 		r.removeModem(path)
 	}()
+
+	go func() {
+		if err := m.Voice().Subscribe(modemCtx, func(call *modem.Call) error {
+			if call.Direction != modem.CallDirectionIncoming {
+				return nil
+			}
+			go r.trackCall(modemCtx, m, call)
+			return nil
+		}); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("modem call subscription stopped", "error", err, "modem", m.EquipmentIdentifier)
+		}
+	}()
 }
 
 func (r *Relay) removeModem(path dbus.ObjectPath) {
@@ -150,7 +180,32 @@ func (r *Relay) stopAll() {
 }
 
 func (r *Relay) forward(m *modem.Modem, message *modem.SMS) error {
-	return r.notifier.Send(r.formatMessage(m, message))
+	formatted := r.formatMessage(m, message)
+	route := match(r.rules, m.EquipmentIdentifier, formatted, time.Now())
+	if route.drop {
+		slog.Debug("message dropped by forwarding rule", "rule", route.rule, "modem", m.EquipmentIdentifier, "from", formatted.From)
+		return nil
+	}
+	var out notify.Message = formatted
+	if route.template != nil {
+		text, err := render(route.template, formatted)
+		if err != nil {
+			slog.Warn("failed to render forwarding template", "rule", route.rule, "error", err)
+		} else {
+			out = notify.FormattedMessage{Message: formatted, Text: text}
+		}
+	}
+	channels := route.channels
+	if len(channels) == 0 {
+		channels = r.notifier.Channels()
+	}
+	var errs error
+	for _, channel := range channels {
+		err := r.notifier.Send(out, channel)
+		metrics.SMSForwarded(channel, err)
+		errs = errors.Join(errs, err)
+	}
+	return errs
 }
 
 func (r *Relay) formatMessage(m *modem.Modem, message *modem.SMS) notify.SMSMessage {
@@ -160,6 +215,7 @@ func (r *Relay) formatMessage(m *modem.Modem, message *modem.SMS) notify.SMSMess
 		sender, recipient = recipient, sender
 	}
 	return notify.SMSMessage{
+		ModemID:  m.EquipmentIdentifier,
 		Modem:    r.modemName(m),
 		From:     sender,
 		To:       recipient,
@@ -0,0 +1,208 @@
+package forwarder
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/notify"
+)
+
+const (
+	actionForward = "forward"
+	actionDrop    = "drop"
+
+	directionIncoming = "incoming"
+	directionOutgoing = "outgoing"
+	directionAny      = "any"
+)
+
+// rule is the compiled form of config.Rule.
+type rule struct {
+	name      string
+	modems    []string
+	sender    *regexp.Regexp
+	text      *regexp.Regexp
+	direction string
+	window    *window
+	drop      bool
+	channels  []string
+	template  *template.Template
+}
+
+// route describes what to do with a message once a rule matched.
+type route struct {
+	rule     string
+	drop     bool
+	channels []string
+	template *template.Template
+}
+
+// window is a time of day range, it wraps around midnight when start > end.
+type window struct {
+	start time.Duration
+	end   time.Duration
+}
+
+func compileTemplates(cfg *config.Config) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(cfg.Templates))
+	for name, text := range cfg.Templates {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template %q: %w", name, err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+func compileRules(cfg *config.Config, templates map[string]*template.Template) ([]rule, error) {
+	rules := make([]rule, 0, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("rules[%d]", i)
+		}
+		compiled := rule{
+			name:     name,
+			modems:   r.Modems,
+			channels: r.Channels,
+		}
+		var err error
+		if compiled.sender, err = compilePattern(r.Sender); err != nil {
+			return nil, fmt.Errorf("rule %s: sender: %w", name, err)
+		}
+		if compiled.text, err = compilePattern(r.Text); err != nil {
+			return nil, fmt.Errorf("rule %s: text: %w", name, err)
+		}
+		// Outgoing messages were never forwarded before rules existed, so a
+		// rule only covers them when asked to.
+		switch direction := strings.ToLower(strings.TrimSpace(r.Direction)); direction {
+		case "":
+			compiled.direction = directionIncoming
+		case directionIncoming, directionOutgoing, directionAny:
+			compiled.direction = direction
+		default:
+			return nil, fmt.Errorf("rule %s: direction must be %s, %s or %s", name, directionIncoming, directionOutgoing, directionAny)
+		}
+		if compiled.window, err = parseWindow(r.Hours); err != nil {
+			return nil, fmt.Errorf("rule %s: hours: %w", name, err)
+		}
+		switch action := strings.ToLower(strings.TrimSpace(r.Action)); action {
+		case "", actionForward:
+		case actionDrop:
+			compiled.drop = true
+		default:
+			return nil, fmt.Errorf("rule %s: action must be %s or %s", name, actionForward, actionDrop)
+		}
+		for _, channel := range r.Channels {
+			if _, ok := cfg.Channels[channel]; !ok {
+				return nil, fmt.Errorf("rule %s: unknown channel %q", name, channel)
+			}
+		}
+		if r.Template != "" {
+			tmpl, ok := templates[r.Template]
+			if !ok {
+				return nil, fmt.Errorf("rule %s: unknown template %q", name, r.Template)
+			}
+			compiled.template = tmpl
+		}
+		rules = append(rules, compiled)
+	}
+	return rules, nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+func parseWindow(raw string) (*window, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(raw, "-")
+	if !ok {
+		return nil, fmt.Errorf("expected HH:MM-HH:MM, got %q", raw)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return nil, err
+	}
+	return &window{start: start, end: end}, nil
+}
+
+func parseClock(raw string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", raw)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (w *window) contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.start <= w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+func (r *rule) matches(modemID string, message notify.SMSMessage, now time.Time) bool {
+	if len(r.modems) > 0 && !slices.Contains(r.modems, modemID) && !slices.Contains(r.modems, message.Modem) {
+		return false
+	}
+	if r.direction == directionIncoming && !message.Incoming {
+		return false
+	}
+	if r.direction == directionOutgoing && message.Incoming {
+		return false
+	}
+	if r.sender != nil && !r.sender.MatchString(message.From) {
+		return false
+	}
+	if r.text != nil && !r.text.MatchString(message.Text) {
+		return false
+	}
+	if r.window != nil && !r.window.contains(now) {
+		return false
+	}
+	return true
+}
+
+// match returns the route of the first matching rule. Incoming messages that
+// match no rule are forwarded to every channel and outgoing ones are dropped,
+// as they were before rules existed.
+func match(rules []rule, modemID string, message notify.SMSMessage, now time.Time) route {
+	for i := range rules {
+		if rules[i].matches(modemID, message, now) {
+			return route{
+				rule:     rules[i].name,
+				drop:     rules[i].drop,
+				channels: rules[i].channels,
+				template: rules[i].template,
+			}
+		}
+	}
+	return route{drop: !message.Incoming}
+}
+
+func render(tmpl *template.Template, message notify.Message) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, message); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -2,12 +2,15 @@ package auth
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/damonto/sigmo/internal/app/auth"
 	"github.com/damonto/sigmo/internal/app/handler"
+	"github.com/damonto/sigmo/internal/app/middleware"
 	"github.com/damonto/sigmo/internal/pkg/config"
 )
 
@@ -41,12 +44,78 @@ func (h *Handler) VerifyOTP(c echo.Context) error {
 	if err := h.BindAndValidate(c, &req); err != nil {
 		return err
 	}
-	token, err := h.service.VerifyOTP(req.Code)
+	token, wait, err := h.service.VerifyOTP(c.RealIP(), c.Request().UserAgent(), req.Code)
 	if err != nil {
-		if errors.Is(err, errInvalidOTP) {
+		if errors.Is(err, auth.ErrTooManyAttempts) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			return h.Error(c, http.StatusTooManyRequests, err)
+		}
+		if errors.Is(err, auth.ErrInvalidOTP) || errors.Is(err, auth.ErrOTPInvalidated) {
 			return h.Unauthorized(c, err)
 		}
 		return h.InternalServerError(c, err)
 	}
 	return h.Respond(c, VerifyOTPResponse{Token: token})
 }
+
+func (h *Handler) ListSessions(c echo.Context) error {
+	session, _ := middleware.Session(c)
+	return h.Respond(c, h.service.ListSessions(session.ID))
+}
+
+func (h *Handler) Logout(c echo.Context) error {
+	session, _ := middleware.Session(c)
+	if err := h.service.RevokeSession(session.ID); err != nil {
+		return h.sessionError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) RevokeSession(c echo.Context) error {
+	if err := h.service.RevokeSession(c.Param("id")); err != nil {
+		return h.sessionError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) sessionError(c echo.Context, err error) error {
+	if errors.Is(err, errNoSession) {
+		return h.BadRequest(c, err)
+	}
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return h.NotFound(c, err)
+	}
+	return h.InternalServerError(c, err)
+}
+
+func (h *Handler) ListAPIKeys(c echo.Context) error {
+	return h.Respond(c, h.service.ListAPIKeys())
+}
+
+func (h *Handler) CreateAPIKey(c echo.Context) error {
+	var req CreateAPIKeyRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	response, err := h.service.CreateAPIKey(req)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidScope) {
+			return h.BadRequest(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	return c.JSON(http.StatusCreated, handler.DataResponse{Data: response})
+}
+
+func (h *Handler) DeleteAPIKey(c echo.Context) error {
+	if err := h.service.DeleteAPIKey(c.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			return h.NotFound(c, err)
+		}
+		if errors.Is(err, auth.ErrAPIKeyReadOnly) {
+			return h.Conflict(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
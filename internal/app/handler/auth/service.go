@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/damonto/sigmo/internal/app/auth"
 	"github.com/damonto/sigmo/internal/pkg/config"
@@ -12,18 +14,20 @@ import (
 
 var (
 	errAuthProviderRequired = errors.New("auth provider is required")
-	errInvalidOTP           = errors.New("invalid otp")
+	errNoSession            = errors.New("request is not authenticated by a session")
 )
 
 type Service struct {
-	cfg   *config.Config
-	store *auth.Store
+	cfg     *config.Config
+	store   *auth.Store
+	limiter *auth.Limiter
 }
 
 func NewService(cfg *config.Config, store *auth.Store) *Service {
 	return &Service{
-		cfg:   cfg,
-		store: store,
+		cfg:     cfg,
+		store:   store,
+		limiter: auth.NewLimiter(),
 	}
 }
 
@@ -43,26 +47,151 @@ func (s *Service) SendOTP() error {
 		slog.Error("failed to issue OTP", "error", err)
 		return err
 	}
-	notifier, err := notify.New(s.cfg)
+	if err := s.notify(fmt.Sprintf("Your verification code is %s", code)); err != nil {
+		slog.Error("failed to send OTP notification", "error", err)
+		return err
+	}
+	return nil
+}
+
+// VerifyOTP exchanges a valid OTP for a token. While the client is locked out
+// it returns auth.ErrTooManyAttempts and how long to wait.
+func (s *Service) VerifyOTP(ip string, userAgent string, code string) (string, time.Duration, error) {
+	if s.OTPRequired() {
+		if wait, err := s.limiter.Allow(ip); err != nil {
+			return "", wait, err
+		}
+		if err := s.store.VerifyOTP(code); err != nil {
+			slog.Warn("failed OTP verification", "ip", ip, "error", err)
+			s.reportFailure(ip, s.limiter.Fail(ip), errors.Is(err, auth.ErrOTPInvalidated))
+			return "", 0, err
+		}
+		s.limiter.Succeed(ip)
+	}
+	token, _, err := s.store.IssueToken(ip, userAgent)
+	if err != nil {
+		slog.Error("failed to issue token", "error", err)
+		return "", 0, err
+	}
+	return token, 0, nil
+}
+
+func (s *Service) ListSessions(currentID string) []SessionResponse {
+	sessions := s.store.Sessions()
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{
+			ID:         session.ID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentID,
+		})
+	}
+	return response
+}
+
+func (s *Service) RevokeSession(id string) error {
+	if id == "" {
+		return errNoSession
+	}
+	if err := s.store.Revoke(id); err != nil {
+		if !errors.Is(err, auth.ErrSessionNotFound) {
+			slog.Error("failed to revoke session", "session", id, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// reportFailure warns the auth providers when a failed attempt invalidated
+// the OTP or locked out a client. It does not block the response, so the
+// timing of a failed attempt does not depend on the notification channels.
+func (s *Service) reportFailure(ip string, lockouts []auth.Lockout, invalidated bool) {
+	var lines []string
+	if invalidated {
+		lines = append(lines, "The verification code was invalidated after too many failed attempts.")
+	}
+	for _, lockout := range lockouts {
+		if lockout.Global {
+			lines = append(lines, fmt.Sprintf("Login is locked for %s after %d failed attempts from all clients.", lockout.Duration, lockout.Failures))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("Login from %s is locked for %s after %d failed attempts.", ip, lockout.Duration, lockout.Failures))
+	}
+	if len(lines) == 0 || len(s.cfg.App.AuthProviders) == 0 {
+		return
+	}
+	for _, line := range lines {
+		slog.Warn("OTP brute-force protection triggered", "ip", ip, "reason", line)
+	}
+	go func() {
+		if err := s.notify(strings.Join(lines, "\n")); err != nil {
+			slog.Error("failed to send lockout notification", "error", err)
+		}
+	}()
+}
+
+func (s *Service) notify(text string) error {
+	notifier, err := notify.New(s.cfg, nil)
 	if err != nil {
 		slog.Error("failed to create notifier", "error", err)
 		return err
 	}
-	if err := notifier.Send(notify.TextMessage{Text: fmt.Sprintf("Your verification code is %s", code)}, s.cfg.App.AuthProviders...); err != nil {
-		slog.Error("failed to send OTP notification", "error", err)
+	return notifier.Send(notify.TextMessage{Text: text}, s.cfg.App.AuthProviders...)
+}
+
+func (s *Service) ListAPIKeys() []APIKeyResponse {
+	keys := s.store.APIKeys()
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, buildAPIKeyResponse(key))
+	}
+	return response
+}
+
+func (s *Service) CreateAPIKey(req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	key, apiKey, err := s.store.CreateAPIKey(req.Name, req.Scopes, req.Modems)
+	if err != nil {
+		if !errors.Is(err, auth.ErrInvalidScope) {
+			slog.Error("failed to create api key", "name", req.Name, "error", err)
+		}
+		return nil, err
+	}
+	return &CreateAPIKeyResponse{APIKeyResponse: buildAPIKeyResponse(apiKey), Key: key}, nil
+}
+
+func (s *Service) DeleteAPIKey(id string) error {
+	if err := s.store.DeleteAPIKey(id); err != nil {
+		if !errors.Is(err, auth.ErrAPIKeyNotFound) && !errors.Is(err, auth.ErrAPIKeyReadOnly) {
+			slog.Error("failed to delete api key", "key", id, "error", err)
+		}
 		return err
 	}
 	return nil
 }
 
-func (s *Service) VerifyOTP(code string) (string, error) {
-	if s.OTPRequired() && !s.store.VerifyOTP(code) {
-		return "", errInvalidOTP
+func buildAPIKeyResponse(key auth.APIKey) APIKeyResponse {
+	response := APIKeyResponse{
+		ID:     key.ID,
+		Name:   key.Name,
+		Scopes: key.Scopes,
+		Modems: key.Modems,
+		Source: "api",
 	}
-	token, _, err := s.store.IssueToken()
-	if err != nil {
-		slog.Error("failed to issue token", "error", err)
-		return "", err
+	if response.Modems == nil {
+		response.Modems = []string{}
+	}
+	if key.Config {
+		response.Source = "config"
+	}
+	if !key.CreatedAt.IsZero() {
+		response.CreatedAt = &key.CreatedAt
+	}
+	if !key.LastUsedAt.IsZero() {
+		response.LastUsedAt = &key.LastUsedAt
 	}
-	return token, nil
+	return response
 }
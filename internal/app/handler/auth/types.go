@@ -1,5 +1,7 @@
 package auth
 
+import "time"
+
 type VerifyOTPRequest struct {
 	Code string `json:"code" validate:"required,len=6,numeric"`
 }
@@ -11,3 +13,34 @@ type VerifyOTPResponse struct {
 type OTPRequirementResponse struct {
 	OTPRequired bool `json:"otpRequired"`
 }
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	Current    bool      `json:"current"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=64"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+	Modems []string `json:"modems"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Modems     []string   `json:"modems"`
+	Source     string     `json:"source"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,123 @@
+package bearer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/damonto/sigmo/internal/app/handler"
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Handler struct {
+	handler.Handler
+	manager *mmodem.Manager
+	service *Service
+}
+
+func New(manager *mmodem.Manager) *Handler {
+	return &Handler{
+		manager: manager,
+		service: NewService(),
+	}
+}
+
+func (h *Handler) List(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	response, err := h.service.List(modem)
+	if err != nil {
+		return h.InternalServerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) Create(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req BearerRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	response, err := h.service.Create(modem, req)
+	if err != nil {
+		return h.bearerError(c, err)
+	}
+	return c.JSON(http.StatusCreated, handler.DataResponse{Data: response})
+}
+
+func (h *Handler) Delete(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	if err := h.service.Delete(modem, c.Param("bearer")); err != nil {
+		return h.bearerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) ConnectBearer(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	response, err := h.service.ConnectBearer(modem, c.Param("bearer"))
+	if err != nil {
+		return h.bearerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) DisconnectBearer(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	if err := h.service.DisconnectBearer(modem, c.Param("bearer")); err != nil {
+		return h.bearerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) Connect(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req BearerRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	response, err := h.service.Connect(modem, req)
+	if err != nil {
+		return h.bearerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) Disconnect(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	if err := h.service.Disconnect(modem); err != nil {
+		return h.InternalServerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) bearerError(c echo.Context, err error) error {
+	if errors.Is(err, mmodem.ErrBearerNotFound) {
+		return h.NotFound(c, err)
+	}
+	if errors.Is(err, mmodem.ErrInvalidIPType) || errors.Is(err, mmodem.ErrInvalidAuth) {
+		return h.BadRequest(c, err)
+	}
+	return h.InternalServerError(c, err)
+}
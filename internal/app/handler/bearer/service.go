@@ -0,0 +1,169 @@
+package bearer
+
+import (
+	"log/slog"
+	"strings"
+
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Service struct{}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) List(modem *mmodem.Modem) ([]BearerResponse, error) {
+	bearers, err := modem.Bearers().List()
+	if err != nil {
+		slog.Error("failed to list bearers", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	response := make([]BearerResponse, 0, len(bearers))
+	for _, bearer := range bearers {
+		response = append(response, buildBearerResponse(bearer))
+	}
+	return response, nil
+}
+
+func (s *Service) Create(modem *mmodem.Modem, req BearerRequest) (*BearerResponse, error) {
+	properties, err := propertiesFrom(req)
+	if err != nil {
+		return nil, err
+	}
+	bearer, err := modem.Bearers().Create(properties)
+	if err != nil {
+		slog.Error("failed to create bearer", "modem", modem.EquipmentIdentifier, "apn", req.APN, "error", err)
+		return nil, err
+	}
+	response := buildBearerResponse(bearer)
+	return &response, nil
+}
+
+func (s *Service) Delete(modem *mmodem.Modem, id string) error {
+	bearer, err := modem.Bearers().Find(id)
+	if err != nil {
+		return err
+	}
+	if err := modem.Bearers().Delete(bearer.Path()); err != nil {
+		slog.Error("failed to delete bearer", "modem", modem.EquipmentIdentifier, "bearer", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) ConnectBearer(modem *mmodem.Modem, id string) (*BearerResponse, error) {
+	bearer, err := modem.Bearers().Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := bearer.Connect(); err != nil {
+		slog.Error("failed to connect bearer", "modem", modem.EquipmentIdentifier, "bearer", id, "error", err)
+		return nil, err
+	}
+	return s.get(modem, id)
+}
+
+func (s *Service) DisconnectBearer(modem *mmodem.Modem, id string) error {
+	bearer, err := modem.Bearers().Find(id)
+	if err != nil {
+		return err
+	}
+	if err := bearer.Disconnect(); err != nil {
+		slog.Error("failed to disconnect bearer", "modem", modem.EquipmentIdentifier, "bearer", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+// Connect brings up data with the given settings, reusing a bearer with the
+// same settings if there is one.
+func (s *Service) Connect(modem *mmodem.Modem, req BearerRequest) (*BearerResponse, error) {
+	properties, err := propertiesFrom(req)
+	if err != nil {
+		return nil, err
+	}
+	bearer, err := modem.Connect(properties)
+	if err != nil {
+		slog.Error("failed to connect", "modem", modem.EquipmentIdentifier, "apn", req.APN, "error", err)
+		return nil, err
+	}
+	response := buildBearerResponse(bearer)
+	return &response, nil
+}
+
+func (s *Service) Disconnect(modem *mmodem.Modem) error {
+	if err := modem.Disconnect(""); err != nil {
+		slog.Error("failed to disconnect", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) get(modem *mmodem.Modem, id string) (*BearerResponse, error) {
+	bearer, err := modem.Bearers().Find(id)
+	if err != nil {
+		return nil, err
+	}
+	response := buildBearerResponse(bearer)
+	return &response, nil
+}
+
+func propertiesFrom(req BearerRequest) (mmodem.BearerProperties, error) {
+	ipType, err := mmodem.ParseBearerIPFamily(req.IPType)
+	if err != nil {
+		return mmodem.BearerProperties{}, err
+	}
+	auth, err := mmodem.ParseBearerAllowedAuth(req.Auth)
+	if err != nil {
+		return mmodem.BearerProperties{}, err
+	}
+	return mmodem.BearerProperties{
+		APN:          strings.TrimSpace(req.APN),
+		IPType:       ipType,
+		AllowedAuth:  auth,
+		User:         req.User,
+		Password:     req.Password,
+		AllowRoaming: req.AllowRoaming,
+	}, nil
+}
+
+func buildBearerResponse(bearer *mmodem.Bearer) BearerResponse {
+	return BearerResponse{
+		ID:           bearer.ID(),
+		Interface:    bearer.Interface,
+		Connected:    bearer.Connected,
+		Suspended:    bearer.Suspended,
+		APN:          bearer.Properties.APN,
+		IPType:       bearer.Properties.IPType.String(),
+		Auth:         bearer.Properties.AllowedAuth.String(),
+		User:         bearer.Properties.User,
+		AllowRoaming: bearer.Properties.AllowRoaming == nil || *bearer.Properties.AllowRoaming,
+		IPv4:         buildIPConfigResponse(bearer.IPv4Config),
+		IPv6:         buildIPConfigResponse(bearer.IPv6Config),
+		Stats: StatsResponse{
+			Duration:       bearer.Stats.Duration,
+			RxBytes:        bearer.Stats.RxBytes,
+			TxBytes:        bearer.Stats.TxBytes,
+			Attempts:       bearer.Stats.Attempts,
+			FailedAttempts: bearer.Stats.FailedAttempts,
+			TotalDuration:  bearer.Stats.TotalDuration,
+			TotalRxBytes:   bearer.Stats.TotalRxBytes,
+			TotalTxBytes:   bearer.Stats.TotalTxBytes,
+		},
+	}
+}
+
+func buildIPConfigResponse(config *mmodem.BearerIPConfig) *IPConfigResponse {
+	if config == nil {
+		return nil
+	}
+	return &IPConfigResponse{
+		Method:  config.Method.String(),
+		Address: config.Address,
+		Prefix:  config.Prefix,
+		Gateway: config.Gateway,
+		DNS:     config.DNS,
+		MTU:     config.MTU,
+	}
+}
@@ -0,0 +1,45 @@
+package bearer
+
+type BearerRequest struct {
+	APN          string `json:"apn"`
+	IPType       string `json:"ipType" validate:"omitempty,oneof=ipv4 ipv6 ipv4v6"`
+	Auth         string `json:"auth" validate:"omitempty,oneof=none pap chap mschap mschapv2 eap"`
+	User         string `json:"user"`
+	Password     string `json:"password"`
+	AllowRoaming *bool  `json:"allowRoaming"`
+}
+
+type IPConfigResponse struct {
+	Method  string   `json:"method"`
+	Address string   `json:"address,omitempty"`
+	Prefix  uint32   `json:"prefix,omitempty"`
+	Gateway string   `json:"gateway,omitempty"`
+	DNS     []string `json:"dns,omitempty"`
+	MTU     uint32   `json:"mtu,omitempty"`
+}
+
+type StatsResponse struct {
+	Duration       uint32 `json:"duration"`
+	RxBytes        uint64 `json:"rxBytes"`
+	TxBytes        uint64 `json:"txBytes"`
+	Attempts       uint32 `json:"attempts"`
+	FailedAttempts uint32 `json:"failedAttempts"`
+	TotalDuration  uint32 `json:"totalDuration"`
+	TotalRxBytes   uint64 `json:"totalRxBytes"`
+	TotalTxBytes   uint64 `json:"totalTxBytes"`
+}
+
+type BearerResponse struct {
+	ID           string            `json:"id"`
+	Interface    string            `json:"interface"`
+	Connected    bool              `json:"connected"`
+	Suspended    bool              `json:"suspended"`
+	APN          string            `json:"apn"`
+	IPType       string            `json:"ipType"`
+	Auth         string            `json:"auth"`
+	User         string            `json:"user,omitempty"`
+	AllowRoaming bool              `json:"allowRoaming"`
+	IPv4         *IPConfigResponse `json:"ipv4,omitempty"`
+	IPv6         *IPConfigResponse `json:"ipv6,omitempty"`
+	Stats        StatsResponse     `json:"stats"`
+}
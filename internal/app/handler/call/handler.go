@@ -0,0 +1,121 @@
+package call
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/damonto/sigmo/internal/app/handler"
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Handler struct {
+	handler.Handler
+	manager *mmodem.Manager
+	service *Service
+}
+
+func New(manager *mmodem.Manager) *Handler {
+	return &Handler{
+		manager: manager,
+		service: NewService(),
+	}
+}
+
+func (h *Handler) List(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	response, err := h.service.List(modem)
+	if err != nil {
+		return h.InternalServerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) Create(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req CreateCallRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	response, err := h.service.Create(modem, req.Number)
+	if err != nil {
+		return h.callError(c, err)
+	}
+	return c.JSON(http.StatusCreated, handler.DataResponse{Data: response})
+}
+
+func (h *Handler) Accept(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	if err := h.service.Accept(modem, c.Param("call")); err != nil {
+		return h.callError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) Hangup(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	if err := h.service.Hangup(modem, c.Param("call")); err != nil {
+		return h.callError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) HangupAll(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	if err := h.service.HangupAll(modem); err != nil {
+		return h.InternalServerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) SendDtmf(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req SendDtmfRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.service.SendDtmf(modem, c.Param("call"), req.Tones); err != nil {
+		return h.callError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) Delete(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	if err := h.service.Delete(modem, c.Param("call")); err != nil {
+		return h.callError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) callError(c echo.Context, err error) error {
+	if errors.Is(err, mmodem.ErrCallNotFound) {
+		return h.NotFound(c, err)
+	}
+	if errors.Is(err, errNumberRequired) || errors.Is(err, errInvalidTones) {
+		return h.BadRequest(c, err)
+	}
+	return h.InternalServerError(c, err)
+}
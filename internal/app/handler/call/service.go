@@ -0,0 +1,135 @@
+package call
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Service struct{}
+
+var (
+	errNumberRequired = errors.New("number is required")
+	errInvalidTones   = errors.New("tones may only contain 0-9, *, #, A-D and ,")
+)
+
+var dtmfRE = regexp.MustCompile(`^[0-9*#A-Da-d,]+$`)
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) List(modem *mmodem.Modem) ([]CallResponse, error) {
+	calls, err := modem.Voice().ListCalls()
+	if err != nil {
+		slog.Error("failed to list calls", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	response := make([]CallResponse, 0, len(calls))
+	for _, call := range calls {
+		response = append(response, buildCallResponse(call))
+	}
+	return response, nil
+}
+
+// Create dials the number and returns the call.
+func (s *Service) Create(modem *mmodem.Modem, number string) (*CallResponse, error) {
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return nil, errNumberRequired
+	}
+	call, err := modem.Voice().CreateCall(number)
+	if err != nil {
+		slog.Error("failed to create call", "modem", modem.EquipmentIdentifier, "number", number, "error", err)
+		return nil, err
+	}
+	if err := call.Start(); err != nil {
+		slog.Error("failed to start call", "modem", modem.EquipmentIdentifier, "number", number, "error", err)
+		if derr := modem.Voice().DeleteCall(call.Path()); derr != nil {
+			slog.Warn("failed to delete call", "modem", modem.EquipmentIdentifier, "error", derr)
+		}
+		return nil, err
+	}
+	return s.get(modem, call.ID())
+}
+
+func (s *Service) Accept(modem *mmodem.Modem, id string) error {
+	call, err := modem.Voice().FindCall(id)
+	if err != nil {
+		return err
+	}
+	if err := call.Accept(); err != nil {
+		slog.Error("failed to accept call", "modem", modem.EquipmentIdentifier, "call", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) Hangup(modem *mmodem.Modem, id string) error {
+	call, err := modem.Voice().FindCall(id)
+	if err != nil {
+		return err
+	}
+	if err := call.Hangup(); err != nil {
+		slog.Error("failed to hang up call", "modem", modem.EquipmentIdentifier, "call", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) HangupAll(modem *mmodem.Modem) error {
+	if err := modem.Voice().HangupAll(); err != nil {
+		slog.Error("failed to hang up calls", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) SendDtmf(modem *mmodem.Modem, id string, tones string) error {
+	if !dtmfRE.MatchString(tones) {
+		return errInvalidTones
+	}
+	call, err := modem.Voice().FindCall(id)
+	if err != nil {
+		return err
+	}
+	if err := call.SendDtmf(strings.ToUpper(tones)); err != nil {
+		slog.Error("failed to send DTMF", "modem", modem.EquipmentIdentifier, "call", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) Delete(modem *mmodem.Modem, id string) error {
+	call, err := modem.Voice().FindCall(id)
+	if err != nil {
+		return err
+	}
+	if err := modem.Voice().DeleteCall(call.Path()); err != nil {
+		slog.Error("failed to delete call", "modem", modem.EquipmentIdentifier, "call", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) get(modem *mmodem.Modem, id string) (*CallResponse, error) {
+	call, err := modem.Voice().FindCall(id)
+	if err != nil {
+		return nil, err
+	}
+	response := buildCallResponse(call)
+	return &response, nil
+}
+
+func buildCallResponse(call *mmodem.Call) CallResponse {
+	return CallResponse{
+		ID:          call.ID(),
+		Number:      call.Number,
+		Direction:   call.Direction.String(),
+		State:       call.State.String(),
+		StateReason: call.StateReason.String(),
+	}
+}
@@ -0,0 +1,17 @@
+package call
+
+type CallResponse struct {
+	ID          string `json:"id"`
+	Number      string `json:"number"`
+	Direction   string `json:"direction"`
+	State       string `json:"state"`
+	StateReason string `json:"stateReason"`
+}
+
+type CreateCallRequest struct {
+	Number string `json:"number" validate:"required"`
+}
+
+type SendDtmfRequest struct {
+	Tones string `json:"tones" validate:"required"`
+}
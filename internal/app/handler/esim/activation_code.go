@@ -8,6 +8,7 @@ import (
 
 	elpa "github.com/damonto/euicc-go/lpa"
 
+	"github.com/damonto/sigmo/internal/pkg/lpa"
 	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
 )
 
@@ -30,16 +31,12 @@ func buildActivationCode(modem *mmodem.Modem, start downloadClientMessage) (*elp
 }
 
 func parseSMDP(raw string) (*url.URL, error) {
-	smdp := strings.TrimSpace(raw)
-	if smdp == "" {
+	if strings.TrimSpace(raw) == "" {
 		return nil, errors.New("smdp is required")
 	}
-	if !strings.Contains(smdp, "://") {
-		smdp = "https://" + smdp
-	}
-	parsed, err := url.Parse(smdp)
-	if err != nil || parsed.Host == "" {
+	address, err := lpa.ParseAddress(raw)
+	if err != nil {
 		return nil, fmt.Errorf("invalid smdp %q", raw)
 	}
-	return &url.URL{Scheme: "https", Host: parsed.Host}, nil
+	return address, nil
 }
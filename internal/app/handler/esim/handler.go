@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 
+	"github.com/damonto/sigmo/internal/app/events"
 	"github.com/damonto/sigmo/internal/app/handler"
 	"github.com/damonto/sigmo/internal/pkg/carrier"
 	"github.com/damonto/sigmo/internal/pkg/config"
@@ -35,11 +37,15 @@ var wsUpgrader = websocket.Upgrader{
 
 const enableTimeout = time.Minute
 
-var errEnableTimeout = errors.New("enabling timed out, please refresh to confirm whether the profile is active")
+var (
+	errEnableTimeout = errors.New("enabling timed out, please refresh to confirm whether the profile is active")
+	errInvalidSafe   = errors.New("safe must be true or false")
+)
 
 const (
 	wsTypeStart                    = "start"
 	wsTypeProgress                 = "progress"
+	wsTypeDiscovered               = "discovered"
 	wsTypePreview                  = "preview"
 	wsTypeConfirm                  = "confirm"
 	wsTypeConfirmationCode         = "confirmation_code"
@@ -49,11 +55,11 @@ const (
 	wsTypeError                    = "error"
 )
 
-func New(cfg *config.Config, manager *mmodem.Manager) *Handler {
+func New(cfg *config.Config, manager *mmodem.Manager, hub *events.Hub) *Handler {
 	return &Handler{
 		cfg:     cfg,
 		manager: manager,
-		service: NewService(cfg, manager),
+		service: NewService(cfg, manager, hub),
 	}
 }
 
@@ -96,9 +102,16 @@ func (h *Handler) Enable(c echo.Context) error {
 	if err != nil {
 		return h.BadRequest(c, err)
 	}
+	safe := h.cfg.Esim.SafeSwitch
+	if raw := c.QueryParam("safe"); raw != "" {
+		if safe, err = strconv.ParseBool(raw); err != nil {
+			return h.BadRequest(c, errInvalidSafe)
+		}
+	}
 	ctx, cancel := context.WithTimeout(c.Request().Context(), enableTimeout)
 	defer cancel()
-	if err := h.service.Enable(ctx, modem, iccid); err != nil {
+	verifying, err := h.service.Enable(ctx, modem, iccid, safe)
+	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return h.Error(c, http.StatusRequestTimeout, errEnableTimeout)
 		}
@@ -110,6 +123,50 @@ func (h *Handler) Enable(c echo.Context) error {
 		}
 		return h.InternalServerError(c, err)
 	}
+	if verifying {
+		// The registration check and a rollback are published as progress.
+		return c.NoContent(http.StatusAccepted)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) Disable(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	iccid, err := iccidFromParam(c)
+	if err != nil {
+		return h.BadRequest(c, err)
+	}
+	if err := h.service.Disable(modem, iccid); err != nil {
+		if errors.Is(err, lpa.ErrNoSupportedAID) {
+			return h.NotFound(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Reset wipes the eUICC. The confirm query parameter must carry its EID.
+func (h *Handler) Reset(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	confirm := c.QueryParam("confirm")
+	if confirm == "" {
+		return h.BadRequest(c, errResetConfirm)
+	}
+	if err := h.service.Reset(modem, confirm); err != nil {
+		if errors.Is(err, errResetConfirm) {
+			return h.BadRequest(c, err)
+		}
+		if errors.Is(err, lpa.ErrNoSupportedAID) {
+			return h.NotFound(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
@@ -149,6 +206,19 @@ func (h *Handler) Download(c echo.Context) error {
 		return nil
 	}
 
+	if start.Discover {
+		event, err := h.service.DiscoverEvent(modem, strings.TrimSpace(start.EventID))
+		if err != nil {
+			_ = conn.WriteJSON(downloadServerMessage{Type: wsTypeError, Message: err.Error()})
+			return nil
+		}
+		if err := conn.WriteJSON(downloadServerMessage{Type: wsTypeDiscovered, Event: event}); err != nil {
+			return nil
+		}
+		start.SMDP = event.Address
+		start.ActivationCode = event.EventID
+	}
+
 	activationCode, err := buildActivationCode(modem, start)
 	if err != nil {
 		_ = conn.WriteJSON(downloadServerMessage{Type: wsTypeError, Message: err.Error()})
@@ -240,7 +310,7 @@ func readStartMessage(conn *websocket.Conn) (downloadClientMessage, error) {
 	if start.Type != "" && start.Type != wsTypeStart {
 		return downloadClientMessage{}, fmt.Errorf("unexpected message type %q", start.Type)
 	}
-	if start.SMDP == "" {
+	if start.SMDP == "" && !start.Discover {
 		return downloadClientMessage{}, errors.New("smdp is required")
 	}
 	return start, nil
@@ -3,13 +3,18 @@ package esim
 import (
 	"context"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 	"unicode/utf8"
 
 	elpa "github.com/damonto/euicc-go/lpa"
 	sgp22 "github.com/damonto/euicc-go/v2"
+
+	"github.com/damonto/sigmo/internal/app/events"
 	"github.com/damonto/sigmo/internal/pkg/carrier"
 	"github.com/damonto/sigmo/internal/pkg/config"
 	"github.com/damonto/sigmo/internal/pkg/lpa"
@@ -19,14 +24,49 @@ import (
 type Service struct {
 	cfg     *config.Config
 	manager *mmodem.Manager
+	hub     *events.Hub
 }
 
-var errInvalidNickname = errors.New("nickname must be valid utf-8 and 64 bytes or fewer")
+var (
+	errInvalidNickname = errors.New("nickname must be valid utf-8 and 64 bytes or fewer")
+	errNoAPN           = errors.New("no APN known for the profile")
+	errNotRegistered   = errors.New("profile did not register")
+	errRolledBack      = errors.New("profile did not register, the previous profile was enabled again")
+	errRollbackFailed  = errors.New("profile did not register and enabling the previous profile failed")
+	errResetConfirm    = errors.New("confirm must be the EID of the eUICC to reset")
+	errNoEvent         = errors.New("no event discovered")
+)
+
+const (
+	defaultRegistrationTimeout = 2 * time.Minute
+	registrationPollInterval   = 2 * time.Second
+	// switchTimeout bounds a rollback, which runs after the response was sent.
+	switchTimeout = time.Minute
+)
+
+const (
+	operationEnable   = "enable"
+	operationDisable  = "disable"
+	operationDelete   = "delete"
+	operationReset    = "reset"
+	operationDownload = "download"
+	operationNickname = "nickname"
+	operationConnect  = "connect"
+	operationRollback = "rollback"
 
-func NewService(cfg *config.Config, manager *mmodem.Manager) *Service {
+	stageStarted   = "started"
+	stageCompleted = "completed"
+	stageFailed    = "failed"
+	// stageRegistering is published while a safe switch waits for the new
+	// profile to register.
+	stageRegistering = "registering"
+)
+
+func NewService(cfg *config.Config, manager *mmodem.Manager, hub *events.Hub) *Service {
 	return &Service{
 		cfg:     cfg,
 		manager: manager,
+		hub:     hub,
 	}
 }
 
@@ -95,7 +135,7 @@ func (s *Service) Discover(modem *mmodem.Modem) ([]DiscoverResponse, error) {
 		return nil, err
 	}
 
-	entries, err := client.Discover(imei)
+	entries, err := client.Discover(imei, s.cfg.Esim.DiscoveryServers)
 	if err != nil {
 		slog.Error("failed to discover profiles", "modem", modem.EquipmentIdentifier, "error", err)
 		return nil, err
@@ -111,11 +151,66 @@ func (s *Service) Discover(modem *mmodem.Modem) ([]DiscoverResponse, error) {
 	return response, nil
 }
 
-func (s *Service) Enable(ctx context.Context, modem *mmodem.Modem, iccid sgp22.ICCID) error {
+// DiscoverEvent discovers the events of the eUICC and returns the one with
+// the given ID, or the first one when the ID is empty.
+func (s *Service) DiscoverEvent(modem *mmodem.Modem, eventID string) (*DiscoverResponse, error) {
+	events, err := s.Discover(modem)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if eventID == "" || event.EventID == eventID {
+			return &event, nil
+		}
+	}
+	return nil, errNoEvent
+}
+
+// Enable switches to the profile. With safe set, the profile must then
+// register on a network or the previously enabled profile is enabled again.
+// That check runs in the background, reported by verifying being true, and
+// its outcome is only published.
+func (s *Service) Enable(ctx context.Context, modem *mmodem.Modem, iccid sgp22.ICCID, safe bool) (verifying bool, err error) {
+	s.progress(modem, operationEnable, iccid.String(), stageStarted, nil)
+	target, previous, err := s.switchProfile(ctx, modem, iccid)
+	if err != nil {
+		s.progress(modem, operationEnable, iccid.String(), stageFailed, err)
+		return false, err
+	}
+	if safe && previous != nil && previous.String() != iccid.String() {
+		go s.verifySwitch(target, iccid, previous)
+		return true, nil
+	}
+	s.progress(modem, operationEnable, iccid.String(), stageCompleted, nil)
+	if s.cfg.Data.AutoConnect {
+		// Connecting waits for the network registration, which may take longer
+		// than the client is willing to wait; the result is published instead.
+		go s.connectData(target, iccid)
+	}
+	return false, nil
+}
+
+// verifySwitch waits for the enabled profile to register and rolls back to
+// the previous profile if it does not.
+func (s *Service) verifySwitch(modem *mmodem.Modem, iccid sgp22.ICCID, previous sgp22.ICCID) {
+	if err := s.verifyRegistration(context.Background(), modem, iccid); err != nil {
+		err = s.rollback(context.Background(), modem, previous, err)
+		s.progress(modem, operationEnable, iccid.String(), stageFailed, err)
+		return
+	}
+	s.progress(modem, operationEnable, iccid.String(), stageCompleted, nil)
+	if s.cfg.Data.AutoConnect {
+		s.connectData(modem, iccid)
+	}
+}
+
+// switchProfile enables the profile, restarts the modem and waits for it to
+// come back. It returns the new modem and the profile enabled before.
+func (s *Service) switchProfile(ctx context.Context, modem *mmodem.Modem, iccid sgp22.ICCID) (*mmodem.Modem, sgp22.ICCID, error) {
 	client, err := lpa.New(modem, s.cfg)
 	if err != nil {
 		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
-		return err
+		return nil, nil, err
 	}
 	closeClient := func() {
 		if client == nil {
@@ -128,40 +223,168 @@ func (s *Service) Enable(ctx context.Context, modem *mmodem.Modem, iccid sgp22.I
 	}
 	defer closeClient()
 
+	var previous sgp22.ICCID
+	profiles, err := client.ListProfile(nil, nil)
+	if err != nil {
+		slog.Error("failed to list profiles", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, nil, err
+	}
+	for _, profile := range profiles {
+		if profile.ProfileState == 1 {
+			previous = profile.ICCID
+		}
+	}
+
 	notifications, err := client.ListNotification()
 	if err != nil {
 		slog.Error("failed to list notifications", "modem", modem.EquipmentIdentifier, "error", err)
-		return err
+		return nil, nil, err
 	}
 	var lastSeq sgp22.SequenceNumber
 	for _, notification := range notifications {
 		lastSeq = max(lastSeq, notification.SequenceNumber)
 	}
 
-	if err := client.EnableProfile(iccid, true); err != nil {
+	if err := client.Enable(iccid); err != nil {
 		slog.Error("failed to enable profile", "modem", modem.EquipmentIdentifier, "iccid", iccid.String(), "error", err)
-		return err
+		return nil, nil, err
 	}
 
 	closeClient()
 
 	if err := modem.Restart(s.cfg.FindModem(modem.EquipmentIdentifier).Compatible); err != nil {
 		slog.Error("failed to restart modem", "modem", modem.EquipmentIdentifier, "error", err)
-		return err
+		return nil, nil, err
 	}
 
 	target, err := s.manager.WaitForModem(ctx, modem.EquipmentIdentifier)
 	if err != nil {
 		slog.Error("failed to wait for modem", "modem", modem.EquipmentIdentifier, "error", err)
-		return err
+		return nil, nil, err
 	}
 	if err := s.sendPendingNotifications(target, lastSeq); err != nil {
 		slog.Warn("failed to handle modem notifications", "error", err, "modem", modem.EquipmentIdentifier)
 	}
-	return nil
+	return target, previous, nil
 }
 
-func (s *Service) Delete(modem *mmodem.Modem, iccid sgp22.ICCID) error {
+// verifyRegistration waits for the modem to register on a network, home or
+// roaming, within the configured registration timeout.
+func (s *Service) verifyRegistration(ctx context.Context, modem *mmodem.Modem, iccid sgp22.ICCID) error {
+	s.progress(modem, operationEnable, iccid.String(), stageRegistering, nil)
+	timeout := s.cfg.Esim.RegistrationTimeout
+	if timeout <= 0 {
+		timeout = defaultRegistrationTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(registrationPollInterval)
+	defer ticker.Stop()
+	for {
+		// The modem object is replaced when ModemManager re-probes it.
+		current, err := s.manager.Find(modem.EquipmentIdentifier)
+		if err == nil {
+			state, err := current.ThreeGPP().RegistrationState()
+			if err == nil && state.Registered() {
+				slog.Info("profile registered", "modem", modem.EquipmentIdentifier, "iccid", iccid.String(), "state", state.String())
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			slog.Warn("profile did not register", "modem", modem.EquipmentIdentifier, "iccid", iccid.String(), "timeout", timeout)
+			return fmt.Errorf("%w within %s", errNotRegistered, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollback re-enables the previous profile after a failed safe switch. It
+// returns errRolledBack, or errRollbackFailed when the previous profile could
+// not be enabled either.
+func (s *Service) rollback(ctx context.Context, modem *mmodem.Modem, previous sgp22.ICCID, cause error) error {
+	slog.Warn("rolling back profile switch", "modem", modem.EquipmentIdentifier, "iccid", previous.String(), "error", cause)
+	s.progress(modem, operationRollback, previous.String(), stageStarted, nil)
+	if current, err := s.manager.Find(modem.EquipmentIdentifier); err == nil {
+		modem = current
+	}
+	ctx, cancel := context.WithTimeout(ctx, switchTimeout)
+	defer cancel()
+	if _, _, err := s.switchProfile(ctx, modem, previous); err != nil {
+		slog.Error("failed to roll back profile switch", "modem", modem.EquipmentIdentifier, "iccid", previous.String(), "error", err)
+		s.progress(modem, operationRollback, previous.String(), stageFailed, err)
+		return fmt.Errorf("%w: %w", errRollbackFailed, err)
+	}
+	s.progress(modem, operationRollback, previous.String(), stageCompleted, nil)
+	return errRolledBack
+}
+
+// connectData brings up data on the enabled profile with the APN configured
+// for its ICCID or MCCMNC, or the default APN of the carrier.
+func (s *Service) connectData(modem *mmodem.Modem, iccid sgp22.ICCID) {
+	var mccmnc string
+	if sim, err := modem.SIMs().Primary(); err != nil {
+		slog.Warn("failed to fetch SIM", "modem", modem.EquipmentIdentifier, "error", err)
+	} else {
+		mccmnc = sim.OperatorIdentifier
+	}
+	properties, err := s.resolveAPN(iccid.String(), mccmnc)
+	if err != nil {
+		slog.Warn("failed to resolve APN", "modem", modem.EquipmentIdentifier, "iccid", iccid.String(), "mccmnc", mccmnc, "error", err)
+		s.progress(modem, operationConnect, iccid.String(), stageFailed, err)
+		return
+	}
+	data := events.EsimProgressData{Operation: operationConnect, ICCID: iccid.String(), Stage: stageStarted, APN: properties.APN}
+	s.hub.Publish(events.TypeEsimProgress, modem.EquipmentIdentifier, data)
+	if _, err := modem.Connect(properties); err != nil {
+		slog.Error("failed to connect", "modem", modem.EquipmentIdentifier, "apn", properties.APN, "error", err)
+		data.Stage = stageFailed
+		data.Error = err.Error()
+	} else {
+		slog.Info("connected", "modem", modem.EquipmentIdentifier, "apn", properties.APN)
+		data.Stage = stageCompleted
+	}
+	s.hub.Publish(events.TypeEsimProgress, modem.EquipmentIdentifier, data)
+}
+
+func (s *Service) resolveAPN(iccid string, mccmnc string) (mmodem.BearerProperties, error) {
+	apn, ok := s.cfg.FindAPN(iccid, mccmnc)
+	if !ok {
+		defaultAPN, ok := carrier.LookupAPN(mccmnc)
+		if !ok {
+			return mmodem.BearerProperties{}, errNoAPN
+		}
+		apn = config.APN{
+			APN:          defaultAPN.Name,
+			IPType:       defaultAPN.IPType,
+			Auth:         defaultAPN.Auth,
+			User:         defaultAPN.User,
+			Password:     defaultAPN.Password,
+			AllowRoaming: s.cfg.Data.AllowRoaming,
+		}
+	}
+	ipType, err := mmodem.ParseBearerIPFamily(apn.IPType)
+	if err != nil {
+		return mmodem.BearerProperties{}, err
+	}
+	auth, err := mmodem.ParseBearerAllowedAuth(apn.Auth)
+	if err != nil {
+		return mmodem.BearerProperties{}, err
+	}
+	return mmodem.BearerProperties{
+		APN:          apn.APN,
+		IPType:       ipType,
+		AllowedAuth:  auth,
+		User:         apn.User,
+		Password:     apn.Password,
+		AllowRoaming: apn.AllowRoaming,
+	}, nil
+}
+
+func (s *Service) Delete(modem *mmodem.Modem, iccid sgp22.ICCID) (err error) {
+	s.progress(modem, operationDelete, iccid.String(), stageStarted, nil)
+	defer func() { s.finish(modem, operationDelete, iccid.String(), err) }()
+
 	client, err := lpa.New(modem, s.cfg)
 	if err != nil {
 		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
@@ -180,7 +403,97 @@ func (s *Service) Delete(modem *mmodem.Modem, iccid sgp22.ICCID) error {
 	return nil
 }
 
-func (s *Service) Download(ctx context.Context, modem *mmodem.Modem, activationCode *elpa.ActivationCode, opts *elpa.DownloadOptions) error {
+// Disable disables the profile, leaving the eUICC without an enabled
+// profile, and restarts the modem so that it drops the network.
+func (s *Service) Disable(modem *mmodem.Modem, iccid sgp22.ICCID) (err error) {
+	s.progress(modem, operationDisable, iccid.String(), stageStarted, nil)
+	defer func() { s.finish(modem, operationDisable, iccid.String(), err) }()
+
+	client, err := lpa.New(modem, s.cfg)
+	if err != nil {
+		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	closeClient := func() {
+		if client == nil {
+			return
+		}
+		if cerr := client.Close(); cerr != nil {
+			slog.Warn("failed to close LPA client", "error", cerr)
+		}
+		client = nil
+	}
+	defer closeClient()
+
+	if err := client.Disable(iccid); err != nil {
+		slog.Error("failed to disable profile", "modem", modem.EquipmentIdentifier, "iccid", iccid.String(), "error", err)
+		return err
+	}
+	closeClient()
+	return s.restart(modem)
+}
+
+// Reset wipes the eUICC with a memory reset. The confirmation must be the EID
+// of the eUICC, so that a request meant for another modem cannot wipe this
+// one.
+func (s *Service) Reset(modem *mmodem.Modem, confirm string) (err error) {
+	client, err := lpa.New(modem, s.cfg)
+	if err != nil {
+		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	closeClient := func() {
+		if client == nil {
+			return
+		}
+		if cerr := client.Close(); cerr != nil {
+			slog.Warn("failed to close LPA client", "error", cerr)
+		}
+		client = nil
+	}
+	defer closeClient()
+
+	eid, err := client.EID()
+	if err != nil {
+		slog.Error("failed to read EID", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(confirm), hex.EncodeToString(eid)) {
+		return errResetConfirm
+	}
+
+	s.progress(modem, operationReset, "", stageStarted, nil)
+	defer func() { s.finish(modem, operationReset, "", err) }()
+	slog.Warn("resetting eUICC memory", "modem", modem.EquipmentIdentifier, "eid", hex.EncodeToString(eid))
+	if err := client.Reset(); err != nil {
+		slog.Error("failed to reset eUICC memory", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	closeClient()
+	return s.restart(modem)
+}
+
+// restart restarts the modem after its enabled profile changed. It does not
+// wait for the modem to come back, as it may have no profile to register.
+func (s *Service) restart(modem *mmodem.Modem) error {
+	if err := modem.Restart(s.cfg.FindModem(modem.EquipmentIdentifier).Compatible); err != nil {
+		slog.Error("failed to restart modem", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) Download(ctx context.Context, modem *mmodem.Modem, activationCode *elpa.ActivationCode, opts *elpa.DownloadOptions) (err error) {
+	s.progress(modem, operationDownload, "", stageStarted, nil)
+	defer func() { s.finish(modem, operationDownload, "", err) }()
+	onProgress := opts.OnProgress
+	opts.OnProgress = func(stage elpa.DownloadStage) {
+		s.progress(modem, operationDownload, "", stage.String(), nil)
+		if onProgress != nil {
+			onProgress(stage)
+		}
+	}
+
 	client, err := lpa.New(modem, s.cfg)
 	if err != nil {
 		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
@@ -199,10 +512,13 @@ func (s *Service) Download(ctx context.Context, modem *mmodem.Modem, activationC
 	return nil
 }
 
-func (s *Service) UpdateNickname(modem *mmodem.Modem, iccid sgp22.ICCID, nickname string) error {
+func (s *Service) UpdateNickname(modem *mmodem.Modem, iccid sgp22.ICCID, nickname string) (err error) {
 	if err := validateNickname(nickname); err != nil {
 		return err
 	}
+	s.progress(modem, operationNickname, iccid.String(), stageStarted, nil)
+	defer func() { s.finish(modem, operationNickname, iccid.String(), err) }()
+
 	client, err := lpa.New(modem, s.cfg)
 	if err != nil {
 		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
@@ -221,6 +537,22 @@ func (s *Service) UpdateNickname(modem *mmodem.Modem, iccid sgp22.ICCID, nicknam
 	return nil
 }
 
+func (s *Service) progress(modem *mmodem.Modem, operation string, iccid string, stage string, err error) {
+	data := events.EsimProgressData{Operation: operation, ICCID: iccid, Stage: stage}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	s.hub.Publish(events.TypeEsimProgress, modem.EquipmentIdentifier, data)
+}
+
+func (s *Service) finish(modem *mmodem.Modem, operation string, iccid string, err error) {
+	if err != nil {
+		s.progress(modem, operation, iccid, stageFailed, err)
+		return
+	}
+	s.progress(modem, operation, iccid, stageCompleted, nil)
+}
+
 func validateNickname(nickname string) error {
 	if !utf8.ValidString(nickname) {
 		return errInvalidNickname
@@ -25,12 +25,17 @@ type downloadClientMessage struct {
 	ConfirmationCode string `json:"confirmationCode,omitempty"`
 	Accept           *bool  `json:"accept,omitempty"`
 	Code             string `json:"code,omitempty"`
+	// Discover downloads the discovered event with EventID, or the first
+	// one, instead of using SMDP and ActivationCode.
+	Discover bool   `json:"discover,omitempty"`
+	EventID  string `json:"eventId,omitempty"`
 }
 
 type downloadServerMessage struct {
 	Type    string                  `json:"type"`
 	Stage   string                  `json:"stage,omitempty"`
 	Profile *downloadProfilePreview `json:"profile,omitempty"`
+	Event   *DiscoverResponse       `json:"event,omitempty"`
 	Message string                  `json:"message,omitempty"`
 }
 
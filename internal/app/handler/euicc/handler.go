@@ -2,6 +2,8 @@ package euicc
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 
@@ -40,3 +42,56 @@ func (h *Handler) Get(c echo.Context) error {
 	}
 	return h.Respond(c, response)
 }
+
+// Export serves the eUICC report as a JSON file named after the EID.
+func (h *Handler) Export(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	response, err := h.service.Export(modem)
+	if err != nil {
+		if errors.Is(err, lpa.ErrNoSupportedAID) {
+			return h.NotFound(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "euicc-"+response.EID+".json"))
+	return c.JSONPretty(http.StatusOK, response, "  ")
+}
+
+func (h *Handler) Addresses(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	response, err := h.service.Addresses(modem)
+	if err != nil {
+		if errors.Is(err, lpa.ErrNoSupportedAID) {
+			return h.NotFound(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) UpdateAddresses(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req UpdateAddressesRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.service.SetDefaultSMDPAddress(modem, req.DefaultSMDPAddress); err != nil {
+		if errors.Is(err, errInvalidAddress) {
+			return h.BadRequest(c, err)
+		}
+		if errors.Is(err, lpa.ErrNoSupportedAID) {
+			return h.NotFound(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
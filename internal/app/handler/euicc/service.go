@@ -1,14 +1,20 @@
 package euicc
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/damonto/sigmo/internal/pkg/config"
 	"github.com/damonto/sigmo/internal/pkg/lpa"
 	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
 )
 
+var errInvalidAddress = errors.New("invalid SM-DP+ address")
+
 type Service struct {
 	cfg *config.Config
 }
@@ -20,6 +26,30 @@ func NewService(cfg *config.Config) *Service {
 }
 
 func (s *Service) Get(modem *mmodem.Modem) (*EuiccResponse, error) {
+	info, err := s.info(modem)
+	if err != nil {
+		return nil, err
+	}
+	response := euiccResponse(info)
+	return &response, nil
+}
+
+// Export returns the eUICC report with the raw EUICCInfo1 and EUICCInfo2.
+func (s *Service) Export(modem *mmodem.Modem) (*ExportResponse, error) {
+	info, err := s.info(modem)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportResponse{
+		EuiccResponse: euiccResponse(info),
+		Modem:         modem.EquipmentIdentifier,
+		ExportedAt:    time.Now().UTC(),
+		RawEUICCInfo1: hex.EncodeToString(info.RawInfo1),
+		RawEUICCInfo2: hex.EncodeToString(info.RawInfo2),
+	}, nil
+}
+
+func (s *Service) info(modem *mmodem.Modem) (*lpa.Info, error) {
 	client, err := lpa.New(modem, s.cfg)
 	if err != nil {
 		if errors.Is(err, lpa.ErrNoSupportedAID) {
@@ -39,10 +69,111 @@ func (s *Service) Get(modem *mmodem.Modem) (*EuiccResponse, error) {
 		slog.Error("failed to fetch eUICC info", "modem", modem.EquipmentIdentifier, "error", err)
 		return nil, err
 	}
-	return &EuiccResponse{
-		EID:          info.EID,
-		FreeSpace:    info.FreeSpace,
-		SASUP:        info.SASUP,
-		Certificates: info.Certificates,
+	return info, nil
+}
+
+func euiccResponse(info *lpa.Info) EuiccResponse {
+	response := EuiccResponse{
+		EID:                         info.EID,
+		FreeSpace:                   info.FreeSpace,
+		SASUP:                       info.SASUP,
+		Certificates:                info.Certificates,
+		ProfileVersion:              info.ProfileVersion,
+		SVN:                         info.SVN,
+		FirmwareVersion:             info.FirmwareVersion,
+		JavaCardVersion:             info.JavaCardVersion,
+		GlobalPlatformVersion:       info.GlobalPlatformVersion,
+		PPVersion:                   info.PPVersion,
+		SASAccreditationNumber:      info.SASAccreditationNumber,
+		Category:                    info.Category,
+		InstalledApplications:       info.InstalledApplications,
+		FreeVolatileMemory:          info.FreeVolatileMemory,
+		UICCCapabilities:            nonNil(info.UICCCapabilities),
+		RSPCapabilities:             nonNil(info.RSPCapabilities),
+		ForbiddenProfilePolicyRules: nonNil(info.ForbiddenProfilePolicyRules),
+		VerificationCIs:             certificateIssuers(info.VerificationCIs),
+		SigningCIs:                  certificateIssuers(info.SigningCIs),
+		EUICCInfo1: EUICCInfo1Response{
+			SVN:             info.Info1.SVN,
+			VerificationCIs: certificateIssuers(info.Info1.VerificationCIs),
+			SigningCIs:      certificateIssuers(info.Info1.SigningCIs),
+		},
+	}
+	if info.CertificationData != nil {
+		response.CertificationData = &CertificationDataResponse{
+			PlatformLabel:    info.CertificationData.PlatformLabel,
+			DiscoveryBaseURL: info.CertificationData.DiscoveryBaseURL,
+		}
+	}
+	return response
+}
+
+func certificateIssuers(issuers []lpa.CertificateIssuer) []CertificateIssuer {
+	response := make([]CertificateIssuer, 0, len(issuers))
+	for _, issuer := range issuers {
+		response = append(response, CertificateIssuer{KeyID: issuer.KeyID, Name: issuer.Name})
+	}
+	return response
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// Addresses returns the default SM-DP+ and root SM-DS addresses configured in
+// the eUICC.
+func (s *Service) Addresses(modem *mmodem.Modem) (*AddressesResponse, error) {
+	client, err := lpa.New(modem, s.cfg)
+	if err != nil {
+		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	defer func() {
+		if cerr := client.Close(); cerr != nil {
+			slog.Warn("failed to close LPA client", "error", cerr)
+		}
+	}()
+
+	addresses, err := client.EUICCConfiguredAddresses()
+	if err != nil {
+		slog.Error("failed to fetch eUICC configured addresses", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	return &AddressesResponse{
+		DefaultSMDPAddress: addresses.DefaultSMDPAddress,
+		RootSMDSAddress:    addresses.RootSMDSAddress,
 	}, nil
 }
+
+// SetDefaultSMDPAddress sets the SM-DP+ the eUICC uses when a profile is
+// downloaded without an activation code. An empty address clears it.
+func (s *Service) SetDefaultSMDPAddress(modem *mmodem.Modem, address string) error {
+	var host string
+	if strings.TrimSpace(address) != "" {
+		parsed, err := lpa.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errInvalidAddress, err)
+		}
+		host = parsed.Host
+	}
+
+	client, err := lpa.New(modem, s.cfg)
+	if err != nil {
+		slog.Error("failed to create LPA client", "modem", modem.EquipmentIdentifier, "error", err)
+		return err
+	}
+	defer func() {
+		if cerr := client.Close(); cerr != nil {
+			slog.Warn("failed to close LPA client", "error", cerr)
+		}
+	}()
+
+	if err := client.SetDefaultDPAddress(host); err != nil {
+		slog.Error("failed to set default SM-DP+ address", "modem", modem.EquipmentIdentifier, "address", host, "error", err)
+		return err
+	}
+	return nil
+}
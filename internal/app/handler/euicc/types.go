@@ -1,8 +1,63 @@
 package euicc
 
+import "time"
+
 type EuiccResponse struct {
-	EID          string   `json:"eid"`
-	FreeSpace    int32    `json:"freeSpace"`
-	SASUP        string   `json:"sasUp"`
-	Certificates []string `json:"certificates"`
+	EID                         string                     `json:"eid"`
+	FreeSpace                   int32                      `json:"freeSpace"`
+	SASUP                       string                     `json:"sasUp"`
+	Certificates                []string                   `json:"certificates"`
+	ProfileVersion              string                     `json:"profileVersion"`
+	SVN                         string                     `json:"svn"`
+	FirmwareVersion             string                     `json:"firmwareVersion"`
+	JavaCardVersion             string                     `json:"javaCardVersion,omitempty"`
+	GlobalPlatformVersion       string                     `json:"globalPlatformVersion,omitempty"`
+	PPVersion                   string                     `json:"ppVersion"`
+	SASAccreditationNumber      string                     `json:"sasAccreditationNumber"`
+	Category                    string                     `json:"category,omitempty"`
+	InstalledApplications       int32                      `json:"installedApplications"`
+	FreeVolatileMemory          int32                      `json:"freeVolatileMemory"`
+	UICCCapabilities            []string                   `json:"uiccCapabilities"`
+	RSPCapabilities             []string                   `json:"rspCapabilities"`
+	ForbiddenProfilePolicyRules []string                   `json:"forbiddenProfilePolicyRules"`
+	CertificationData           *CertificationDataResponse `json:"certificationData,omitempty"`
+	VerificationCIs             []CertificateIssuer        `json:"verificationCis"`
+	SigningCIs                  []CertificateIssuer        `json:"signingCis"`
+	EUICCInfo1                  EUICCInfo1Response         `json:"euiccInfo1"`
+}
+
+type EUICCInfo1Response struct {
+	SVN             string              `json:"svn"`
+	VerificationCIs []CertificateIssuer `json:"verificationCis"`
+	SigningCIs      []CertificateIssuer `json:"signingCis"`
+}
+
+type CertificationDataResponse struct {
+	PlatformLabel    string `json:"platformLabel"`
+	DiscoveryBaseURL string `json:"discoveryBaseUrl"`
+}
+
+type CertificateIssuer struct {
+	KeyID string `json:"keyId"`
+	Name  string `json:"name,omitempty"`
+}
+
+// ExportResponse is the downloadable eUICC report, with the raw EUICCInfo1
+// and EUICCInfo2 as hex for tools that decode them on their own.
+type ExportResponse struct {
+	EuiccResponse
+	Modem         string    `json:"modem"`
+	ExportedAt    time.Time `json:"exportedAt"`
+	RawEUICCInfo1 string    `json:"rawEuiccInfo1"`
+	RawEUICCInfo2 string    `json:"rawEuiccInfo2"`
+}
+
+type AddressesResponse struct {
+	DefaultSMDPAddress string `json:"defaultSmdpAddress"`
+	RootSMDSAddress    string `json:"rootSmdsAddress"`
+}
+
+type UpdateAddressesRequest struct {
+	// DefaultSMDPAddress is a host or an HTTPS URL; empty clears it.
+	DefaultSMDPAddress string `json:"defaultSmdpAddress" validate:"max=255"`
 }
@@ -0,0 +1,195 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+
+	"github.com/damonto/sigmo/internal/app/auth"
+	"github.com/damonto/sigmo/internal/app/events"
+	"github.com/damonto/sigmo/internal/app/handler"
+	appmiddleware "github.com/damonto/sigmo/internal/app/middleware"
+)
+
+type Handler struct {
+	handler.Handler
+	hub *events.Hub
+}
+
+const (
+	keepaliveInterval = 30 * time.Second
+	writeTimeout      = 10 * time.Second
+)
+
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// categoryScopes maps event categories to the API key scope needed to see them.
+var categoryScopes = map[string]string{
+	"modem": auth.ScopeModemsRead,
+	"sms":   auth.ScopeMessagesRead,
+	"ussd":  auth.ScopeUSSD,
+	"esim":  auth.ScopeEsimRead,
+	"call":  auth.ScopeCalls,
+}
+
+func New(hub *events.Hub) *Handler {
+	return &Handler{hub: hub}
+}
+
+// Stream pushes events over a WebSocket when the client asks for an upgrade
+// and as Server-Sent Events otherwise.
+func (h *Handler) Stream(c echo.Context) error {
+	filter := filterFromRequest(c)
+	if websocket.IsWebSocketUpgrade(c.Request()) {
+		return h.streamWebSocket(c, filter)
+	}
+	return h.streamSSE(c, filter)
+}
+
+func (h *Handler) streamSSE(c echo.Context, filter filter) error {
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	ch, cancel := h.hub.Subscribe()
+	defer cancel()
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if !filter.match(event) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
+
+func (h *Handler) streamWebSocket(c echo.Context, filter filter) error {
+	conn, err := wsUpgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The stream is one-way; reading only detects the client going away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ch, cancel := h.hub.Subscribe()
+	defer cancel()
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return nil
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return nil
+			}
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if !filter.match(event) {
+				continue
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// filter selects the events a client receives, combining the query filters
+// with the restrictions of the API key it authenticated with.
+type filter struct {
+	types  []string
+	modems []string
+	key    *auth.APIKey
+}
+
+func filterFromRequest(c echo.Context) filter {
+	var f filter
+	f.types = splitList(c.QueryParam("types"))
+	f.modems = splitList(c.QueryParam("modem"))
+	if key, ok := appmiddleware.APIKey(c); ok {
+		f.key = &key
+	}
+	return f
+}
+
+func (f filter) match(event events.Event) bool {
+	if len(f.types) > 0 && !slices.ContainsFunc(f.types, func(t string) bool {
+		return t == event.Type || t == event.Category()
+	}) {
+		return false
+	}
+	if len(f.modems) > 0 && !slices.Contains(f.modems, event.Modem) {
+		return false
+	}
+	if f.key != nil {
+		scope, ok := categoryScopes[event.Category()]
+		if !ok || !f.key.Allows(scope) {
+			return false
+		}
+		if event.Modem != "" && !f.key.AllowsModem(event.Modem) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -14,6 +15,11 @@ type DataResponse struct {
 	Data any `json:"data"`
 }
 
+type PageResponse struct {
+	Data       any    `json:"data"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
 type HTTPError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -27,6 +33,10 @@ func (*Handler) Respond(c echo.Context, data any) error {
 	return c.JSON(http.StatusOK, DataResponse{Data: data})
 }
 
+func (*Handler) RespondPage(c echo.Context, data any, nextCursor string) error {
+	return c.JSON(http.StatusOK, PageResponse{Data: data, NextCursor: nextCursor})
+}
+
 func (*Handler) BindAndValidate(c echo.Context, i any) error {
 	if err := c.Bind(i); err != nil {
 		return c.JSON(http.StatusBadRequest, HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
@@ -58,14 +68,12 @@ func (*Handler) InternalServerError(c echo.Context, err error) error {
 }
 
 func (h *Handler) FindModem(manager *modem.Manager, id string) (*modem.Modem, error) {
-	modems, err := manager.Modems()
+	m, err := manager.Find(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list modems: %w", err)
-	}
-	for _, m := range modems {
-		if m.EquipmentIdentifier == id {
-			return m, nil
+		if errors.Is(err, modem.ErrModemNotFound) {
+			return nil, fmt.Errorf("modem with ID %s not found", id)
 		}
+		return nil, fmt.Errorf("failed to list modems: %w", err)
 	}
-	return nil, fmt.Errorf("modem with ID %s not found", id)
+	return m, nil
 }
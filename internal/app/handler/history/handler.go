@@ -0,0 +1,79 @@
+package history
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/damonto/sigmo/internal/app/handler"
+	"github.com/damonto/sigmo/internal/app/history"
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Handler struct {
+	handler.Handler
+	manager *mmodem.Manager
+	service *Service
+}
+
+var (
+	errInvalidTime = errors.New("from and to must be RFC 3339 timestamps")
+	errInvalidStep = errors.New("step must be a positive duration such as 15m")
+)
+
+func New(manager *mmodem.Manager, store *history.Store) *Handler {
+	return &Handler{
+		manager: manager,
+		service: NewService(store),
+	}
+}
+
+func (h *Handler) List(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req ListHistoryRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return h.BadRequest(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return h.BadRequest(c, err)
+	}
+	from, err := parseTime(req.From)
+	if err != nil {
+		return h.BadRequest(c, err)
+	}
+	to, err := parseTime(req.To)
+	if err != nil {
+		return h.BadRequest(c, err)
+	}
+	var step time.Duration
+	if raw := strings.TrimSpace(req.Step); raw != "" {
+		if step, err = time.ParseDuration(raw); err != nil || step <= 0 {
+			return h.BadRequest(c, errInvalidStep)
+		}
+	}
+	response, err := h.service.List(modem, from, to, step, req.Points)
+	if err != nil {
+		if errors.Is(err, errInvalidRange) {
+			return h.BadRequest(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func parseTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errInvalidTime
+	}
+	return t, nil
+}
@@ -0,0 +1,149 @@
+package history
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/damonto/sigmo/internal/app/history"
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Service struct {
+	store *history.Store
+}
+
+const (
+	defaultRange  = 24 * time.Hour
+	defaultPoints = 500
+)
+
+var errInvalidRange = errors.New("from must be before to")
+
+func NewService(store *history.Store) *Service {
+	return &Service{store: store}
+}
+
+// List returns the samples between from and to, downsampled to one point per
+// step. Without a step, the step is chosen so there are at most points points.
+func (s *Service) List(modem *mmodem.Modem, from time.Time, to time.Time, step time.Duration, points int) (*HistoryResponse, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultRange)
+	}
+	if !from.Before(to) {
+		return nil, errInvalidRange
+	}
+	if points <= 0 {
+		points = defaultPoints
+	}
+	// Never return more than points points, even with a small step.
+	step = max(step, (to.Sub(from)+time.Duration(points)-1)/time.Duration(points), time.Second)
+
+	samples, err := s.store.Samples(modem.EquipmentIdentifier, from, to)
+	if err != nil {
+		slog.Error("failed to list signal history", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	return &HistoryResponse{
+		From:   from,
+		To:     to,
+		Step:   int64(step / time.Second),
+		Points: downsample(samples, from, step),
+	}, nil
+}
+
+// downsample merges the samples, oldest first, into points of step duration
+// aligned to from. Steps without samples are left out.
+func downsample(samples []history.Sample, from time.Time, step time.Duration) []PointResponse {
+	points := make([]PointResponse, 0)
+	var acc accumulator
+	bucket := int64(-1)
+	for _, sample := range samples {
+		index := int64(sample.Time.Sub(from) / step)
+		if index != bucket {
+			if acc.count > 0 {
+				points = append(points, acc.point(from.Add(time.Duration(bucket)*step)))
+			}
+			acc = accumulator{}
+			bucket = index
+		}
+		acc.add(sample)
+	}
+	if acc.count > 0 {
+		points = append(points, acc.point(from.Add(time.Duration(bucket)*step)))
+	}
+	return points
+}
+
+type accumulator struct {
+	count      int
+	quality    float64
+	minQuality uint32
+	maxQuality uint32
+	registered int
+	last       history.Sample
+	rssi       average
+	rsrp       average
+	rsrq       average
+	snr        average
+}
+
+func (a *accumulator) add(sample history.Sample) {
+	if a.count == 0 || sample.Quality < a.minQuality {
+		a.minQuality = sample.Quality
+	}
+	a.maxQuality = max(a.maxQuality, sample.Quality)
+	a.count++
+	a.quality += float64(sample.Quality)
+	if sample.Registered {
+		a.registered++
+	}
+	a.rssi.add(sample.RSSI)
+	a.rsrp.add(sample.RSRP)
+	a.rsrq.add(sample.RSRQ)
+	a.snr.add(sample.SNR)
+	a.last = sample
+}
+
+func (a *accumulator) point(t time.Time) PointResponse {
+	return PointResponse{
+		Time:              t,
+		Samples:           a.count,
+		Quality:           a.quality / float64(a.count),
+		MinQuality:        a.minQuality,
+		MaxQuality:        a.maxQuality,
+		Registered:        float64(a.registered) / float64(a.count),
+		RegistrationState: a.last.RegistrationState,
+		AccessTechnology:  a.last.AccessTechnology,
+		OperatorCode:      a.last.OperatorCode,
+		OperatorName:      a.last.OperatorName,
+		RSSI:              a.rssi.value(),
+		RSRP:              a.rsrp.value(),
+		RSRQ:              a.rsrq.value(),
+		SNR:               a.snr.value(),
+	}
+}
+
+type average struct {
+	sum   float64
+	count int
+}
+
+func (a *average) add(v *float64) {
+	if v == nil {
+		return
+	}
+	a.sum += *v
+	a.count++
+}
+
+func (a *average) value() *float64 {
+	if a.count == 0 {
+		return nil
+	}
+	v := a.sum / float64(a.count)
+	return &v
+}
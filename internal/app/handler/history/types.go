@@ -0,0 +1,36 @@
+package history
+
+import "time"
+
+type ListHistoryRequest struct {
+	From   string `query:"from"`
+	To     string `query:"to"`
+	Step   string `query:"step"`
+	Points int    `query:"points" validate:"gte=0,lte=5000"`
+}
+
+type HistoryResponse struct {
+	From   time.Time       `json:"from"`
+	To     time.Time       `json:"to"`
+	Step   int64           `json:"step"`
+	Points []PointResponse `json:"points"`
+}
+
+// PointResponse aggregates the samples of one step. Numbers are averages,
+// text values are taken from the last sample of the step.
+type PointResponse struct {
+	Time              time.Time `json:"time"`
+	Samples           int       `json:"samples"`
+	Quality           float64   `json:"quality"`
+	MinQuality        uint32    `json:"minQuality"`
+	MaxQuality        uint32    `json:"maxQuality"`
+	Registered        float64   `json:"registered"`
+	RegistrationState string    `json:"registrationState"`
+	AccessTechnology  string    `json:"accessTechnology"`
+	OperatorCode      string    `json:"operatorCode,omitempty"`
+	OperatorName      string    `json:"operatorName,omitempty"`
+	RSSI              *float64  `json:"rssi,omitempty"`
+	RSRP              *float64  `json:"rsrp,omitempty"`
+	RSRQ              *float64  `json:"rsrq,omitempty"`
+	SNR               *float64  `json:"snr,omitempty"`
+}
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/damonto/sigmo/internal/app/archive"
+	"github.com/damonto/sigmo/internal/app/events"
 	"github.com/damonto/sigmo/internal/app/handler"
 	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
 )
@@ -18,10 +22,14 @@ type Handler struct {
 	service *Service
 }
 
-func New(manager *mmodem.Manager) *Handler {
+const dateLayout = "2006-01-02"
+
+var errInvalidDate = errors.New("dates must be RFC 3339 timestamps or YYYY-MM-DD")
+
+func New(manager *mmodem.Manager, store *archive.Store, hub *events.Hub) *Handler {
 	return &Handler{
 		manager: manager,
-		service: NewService(),
+		service: NewService(store, hub),
 	}
 }
 
@@ -30,11 +38,18 @@ func (h *Handler) List(c echo.Context) error {
 	if err != nil {
 		return h.NotFound(c, err)
 	}
-	response, err := h.service.ListConversations(modem)
+	query, err := queryFromRequest(c)
+	if err != nil {
+		return h.BadRequest(c, err)
+	}
+	response, next, err := h.service.ListConversations(modem, query)
 	if err != nil {
+		if errors.Is(err, archive.ErrInvalidCursor) {
+			return h.BadRequest(c, err)
+		}
 		return h.InternalServerError(c, err)
 	}
-	return h.Respond(c, response)
+	return h.RespondPage(c, response, next)
 }
 
 func (h *Handler) ListByParticipant(c echo.Context) error {
@@ -46,14 +61,18 @@ func (h *Handler) ListByParticipant(c echo.Context) error {
 	if err != nil {
 		return h.BadRequest(c, err)
 	}
-	response, err := h.service.ListByParticipant(modem, participant)
+	query, err := queryFromRequest(c)
 	if err != nil {
-		if errors.Is(err, errParticipantRequired) {
+		return h.BadRequest(c, err)
+	}
+	response, next, err := h.service.ListByParticipant(modem, participant, query)
+	if err != nil {
+		if errors.Is(err, errParticipantRequired) || errors.Is(err, archive.ErrInvalidCursor) {
 			return h.BadRequest(c, err)
 		}
 		return h.InternalServerError(c, err)
 	}
-	return h.Respond(c, response)
+	return h.RespondPage(c, response, next)
 }
 
 func (h *Handler) Send(c echo.Context) error {
@@ -65,13 +84,14 @@ func (h *Handler) Send(c echo.Context) error {
 	if err := h.BindAndValidate(c, &req); err != nil {
 		return err
 	}
-	if err := h.service.Send(modem, req.To, req.Text); err != nil {
+	response, err := h.service.Send(modem, req.To, req.Text)
+	if err != nil {
 		if errors.Is(err, errRecipientRequired) || errors.Is(err, errTextRequired) {
 			return h.BadRequest(c, err)
 		}
 		return h.InternalServerError(c, err)
 	}
-	return c.NoContent(http.StatusNoContent)
+	return c.JSON(http.StatusCreated, handler.DataResponse{Data: response})
 }
 
 func (h *Handler) DeleteByParticipant(c echo.Context) error {
@@ -92,6 +112,51 @@ func (h *Handler) DeleteByParticipant(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func queryFromRequest(c echo.Context) (archive.Query, error) {
+	var req ListMessagesRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return archive.Query{}, err
+	}
+	if err := c.Validate(&req); err != nil {
+		return archive.Query{}, err
+	}
+	from, err := parseDate(req.From, false)
+	if err != nil {
+		return archive.Query{}, err
+	}
+	to, err := parseDate(req.To, true)
+	if err != nil {
+		return archive.Query{}, err
+	}
+	return archive.Query{
+		Search: strings.TrimSpace(req.Search),
+		From:   from,
+		To:     to,
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+	}, nil
+}
+
+// parseDate accepts RFC 3339 timestamps and plain dates. A plain date used as
+// an upper bound covers the whole day.
+func parseDate(raw string, endOfDay bool) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, raw, time.Local)
+	if err != nil {
+		return time.Time{}, errInvalidDate
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
 func participantFromParam(c echo.Context) (string, error) {
 	raw := c.Param("participant")
 	if raw == "" {
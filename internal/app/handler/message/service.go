@@ -4,13 +4,19 @@ import (
 	"errors"
 	"log/slog"
 	"slices"
-	"strconv"
 	"strings"
+	"time"
 
+	"github.com/damonto/sigmo/internal/app/archive"
+	"github.com/damonto/sigmo/internal/app/events"
+	"github.com/damonto/sigmo/internal/pkg/metrics"
 	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
 )
 
-type Service struct{}
+type Service struct {
+	store *archive.Store
+	hub   *events.Hub
+}
 
 var (
 	errParticipantRequired = errors.New("participant is required")
@@ -18,85 +24,73 @@ var (
 	errTextRequired        = errors.New("text is required")
 )
 
-func NewService() *Service {
-	return &Service{}
+func NewService(store *archive.Store, hub *events.Hub) *Service {
+	return &Service{store: store, hub: hub}
 }
 
-func (s *Service) ListConversations(modem *mmodem.Modem) ([]MessageResponse, error) {
-	messages, err := modem.Messaging().List()
+func (s *Service) ListConversations(modem *mmodem.Modem, query archive.Query) ([]MessageResponse, string, error) {
+	messages, next, err := s.store.Conversations(modem.EquipmentIdentifier, query)
 	if err != nil {
-		slog.Error("failed to list messages", "modem", modem.EquipmentIdentifier, "error", err)
-		return nil, err
+		slog.Error("failed to list conversations", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, "", err
 	}
-
-	latest := make(map[string]*mmodem.SMS, len(messages))
-	for _, sms := range messages {
-		key := strings.TrimSpace(sms.Number)
-		existing, ok := latest[key]
-		if !ok || sms.Timestamp.After(existing.Timestamp) {
-			latest[key] = sms
-		}
-	}
-
-	response := make([]MessageResponse, 0, len(latest))
-	for _, sms := range latest {
-		response = append(response, buildMessageResponse(sms))
+	response := make([]MessageResponse, 0, len(messages))
+	for _, message := range messages {
+		response = append(response, buildMessageResponse(message))
 	}
-
-	slices.SortFunc(response, func(a, b MessageResponse) int {
-		if a.ID == b.ID {
-			return 0
-		}
-		if a.ID > b.ID {
-			return -1
-		}
-		return 1
-	})
-	return response, nil
+	return response, next, nil
 }
 
-func (s *Service) ListByParticipant(modem *mmodem.Modem, participant string) ([]MessageResponse, error) {
+func (s *Service) ListByParticipant(modem *mmodem.Modem, participant string, query archive.Query) ([]MessageResponse, string, error) {
 	if strings.TrimSpace(participant) == "" {
-		return nil, errParticipantRequired
+		return nil, "", errParticipantRequired
 	}
-	messages, err := modem.Messaging().List()
+	query.Participant = participant
+	messages, next, err := s.store.Messages(modem.EquipmentIdentifier, query)
 	if err != nil {
 		slog.Error("failed to list messages", "modem", modem.EquipmentIdentifier, "error", err)
-		return nil, err
+		return nil, "", err
 	}
-
+	// Pages walk backwards in time, but a thread reads oldest first.
+	slices.Reverse(messages)
 	response := make([]MessageResponse, 0, len(messages))
-	for _, sms := range messages {
-		if strings.TrimSpace(sms.Number) != participant {
-			continue
-		}
-		response = append(response, buildMessageResponse(sms))
+	for _, message := range messages {
+		response = append(response, buildMessageResponse(message))
 	}
-	slices.SortFunc(response, func(a, b MessageResponse) int {
-		if a.ID == b.ID {
-			return 0
-		}
-		if a.ID < b.ID {
-			return -1
-		}
-		return 1
-	})
-	return response, nil
+	return response, next, nil
 }
 
-func (s *Service) Send(modem *mmodem.Modem, to string, text string) error {
+// Send sends the message. The archive.Ingester follows its delivery, the
+// archived message and sms.status events report its status.
+func (s *Service) Send(modem *mmodem.Modem, to string, text string) (*MessageResponse, error) {
 	if strings.TrimSpace(to) == "" {
-		return errRecipientRequired
+		return nil, errRecipientRequired
 	}
 	if strings.TrimSpace(text) == "" {
-		return errTextRequired
+		return nil, errTextRequired
 	}
-	_, err := modem.Messaging().Send(to, text)
+	sms, err := modem.Messaging().Send(to, text)
+	metrics.SMSSent(modem.EquipmentIdentifier, err)
 	if err != nil {
 		slog.Error("failed to send SMS", "modem", modem.EquipmentIdentifier, "to", to, "error", err)
-		return err
+		s.hub.Publish(events.TypeSMSFailed, modem.EquipmentIdentifier, events.SMSData{
+			Number:    to,
+			Text:      text,
+			Timestamp: time.Now(),
+			Status:    archive.StatusFailed,
+			Error:     err.Error(),
+		})
+		return nil, err
 	}
-	return nil
+	message, err := s.store.Save(modem.EquipmentIdentifier, sms)
+	if err != nil {
+		// The SMS is sent, failing here would only make the caller send it
+		// again.
+		slog.Error("failed to archive sent SMS", "modem", modem.EquipmentIdentifier, "error", err)
+		message = archive.MessageOf(sms)
+	}
+	response := buildMessageResponse(*message)
+	return &response, nil
 }
 
 func (s *Service) DeleteByParticipant(modem *mmodem.Modem, participant string) error {
@@ -118,35 +112,30 @@ func (s *Service) DeleteByParticipant(modem *mmodem.Modem, participant string) e
 			return err
 		}
 	}
-	return nil
-}
-
-func buildMessageResponse(sms *mmodem.SMS) MessageResponse {
-	incoming := sms.State == mmodem.SMSStateReceived || sms.State == mmodem.SMSStateReceiving
-	remote := strings.TrimSpace(sms.Number)
-	return MessageResponse{
-		ID:        messageID(sms),
-		Sender:    remote,
-		Recipient: remote,
-		Text:      sms.Text,
-		Timestamp: sms.Timestamp,
-		Status:    strings.ToLower(sms.State.String()),
-		Incoming:  incoming,
+	if err := s.store.DeleteByParticipant(modem.EquipmentIdentifier, participant); err != nil {
+		slog.Error("failed to delete archived messages", "modem", modem.EquipmentIdentifier, "participant", participant, "error", err)
+		return err
 	}
+	return nil
 }
 
-func messageID(sms *mmodem.SMS) int64 {
-	path := string(sms.Path())
-	if path == "" {
-		return 0
-	}
-	idx := strings.LastIndex(path, "/")
-	if idx == -1 || idx+1 >= len(path) {
-		return 0
-	}
-	id, err := strconv.ParseInt(path[idx+1:], 10, 64)
-	if err != nil {
-		return 0
-	}
-	return id
+func buildMessageResponse(message archive.Message) MessageResponse {
+	response := MessageResponse{
+		ID:           int64(message.ID),
+		Sender:       message.Number,
+		Recipient:    message.Number,
+		Text:         message.Text,
+		Timestamp:    message.Timestamp,
+		Status:       message.Status,
+		StatusReason: message.Reason,
+		Incoming:     message.Incoming,
+	}
+	if response.Status == "" {
+		// Archived before statuses were tracked.
+		response.Status = message.State
+	}
+	if !message.DeliveredAt.IsZero() {
+		response.DeliveredAt = &message.DeliveredAt
+	}
+	return response
 }
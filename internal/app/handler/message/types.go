@@ -3,16 +3,26 @@ package message
 import "time"
 
 type MessageResponse struct {
-	ID        int64     `json:"id"`
-	Sender    string    `json:"sender"`
-	Recipient string    `json:"recipient"`
-	Text      string    `json:"text"`
-	Timestamp time.Time `json:"timestamp"`
-	Status    string    `json:"status"`
-	Incoming  bool      `json:"incoming"`
+	ID           int64      `json:"id"`
+	Sender       string     `json:"sender"`
+	Recipient    string     `json:"recipient"`
+	Text         string     `json:"text"`
+	Timestamp    time.Time  `json:"timestamp"`
+	Status       string     `json:"status"`
+	StatusReason string     `json:"statusReason,omitempty"`
+	DeliveredAt  *time.Time `json:"deliveredAt,omitempty"`
+	Incoming     bool       `json:"incoming"`
 }
 
 type SendMessageRequest struct {
 	To   string `json:"to"`
 	Text string `json:"text"`
 }
+
+type ListMessagesRequest struct {
+	Search string `query:"q"`
+	From   string `query:"from"`
+	To     string `query:"to"`
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit" validate:"gte=0,lte=500"`
+}
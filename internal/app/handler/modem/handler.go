@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/damonto/sigmo/internal/app/handler"
+	"github.com/damonto/sigmo/internal/app/middleware"
 	"github.com/damonto/sigmo/internal/pkg/config"
 	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
 )
@@ -41,6 +43,9 @@ func (h *Handler) List(c echo.Context) error {
 	if err != nil {
 		return h.InternalServerError(c, err)
 	}
+	response = slices.DeleteFunc(response, func(m *ModemResponse) bool {
+		return !middleware.AllowsModem(c, m.ID)
+	})
 	return h.Respond(c, response)
 }
 
@@ -135,3 +140,33 @@ func (h *Handler) GetSettings(c echo.Context) error {
 	response := h.service.GetSettings(modem.EquipmentIdentifier)
 	return h.Respond(c, response)
 }
+
+func (h *Handler) Signal(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	response, err := h.service.Signal(modem)
+	if err != nil {
+		return h.InternalServerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) SetupSignal(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req SetupSignalRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.service.SetupSignal(modem, req); err != nil {
+		if errors.Is(err, errRateRequired) {
+			return h.BadRequest(c, err)
+		}
+		return h.InternalServerError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -27,6 +27,7 @@ var (
 	errSimSlotAlreadyActive  = errors.New("sim slot already active")
 	errMSISDNInvalidNumber   = errors.New("invalid phone number")
 	errCompatibleRequired    = errors.New("compatible is required")
+	errRateRequired          = errors.New("rate is required")
 )
 
 var msisdnPhoneRE = regexp.MustCompile(`^\+?[0-9]{1,15}$`)
@@ -148,7 +149,59 @@ func (s *Service) GetSettings(modemID string) *ModemSettingsResponse {
 	}
 }
 
+// Signal returns the extended signal information. ModemManager only refreshes
+// it while a rate is set, see SetupSignal.
+func (s *Service) Signal(modem *mmodem.Modem) (*SignalResponse, error) {
+	info, err := modem.Signal().Get()
+	if err != nil {
+		slog.Error("failed to fetch signal information", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	percent, _, err := modem.SignalQuality()
+	if err != nil {
+		slog.Error("failed to fetch signal quality", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	return &SignalResponse{
+		Rate:    info.Rate,
+		Quality: percent,
+		Gsm:     buildSignalMeasurementResponse(info.Gsm),
+		Umts:    buildSignalMeasurementResponse(info.Umts),
+		Lte:     buildSignalMeasurementResponse(info.Lte),
+		Nr5g:    buildSignalMeasurementResponse(info.Nr5g),
+	}, nil
+}
+
+func (s *Service) SetupSignal(modem *mmodem.Modem, req SetupSignalRequest) error {
+	if req.Rate == nil {
+		return errRateRequired
+	}
+	if err := modem.Signal().Setup(*req.Rate); err != nil {
+		slog.Error("failed to set up signal refresh", "modem", modem.EquipmentIdentifier, "rate", *req.Rate, "error", err)
+		return err
+	}
+	return nil
+}
+
+func buildSignalMeasurementResponse(m *mmodem.SignalMeasurement) *SignalMeasurementResponse {
+	if m == nil {
+		return nil
+	}
+	return &SignalMeasurementResponse{
+		RSSI:      m.RSSI,
+		RSCP:      m.RSCP,
+		ECIO:      m.ECIO,
+		RSRP:      m.RSRP,
+		RSRQ:      m.RSRQ,
+		SNR:       m.SNR,
+		ErrorRate: m.ErrorRate,
+	}
+}
+
 func (s *Service) buildModemResponse(m *mmodem.Modem) (*ModemResponse, error) {
+	if m.State == mmodem.ModemStateLocked {
+		return s.buildLockedModemResponse(m), nil
+	}
 	sim, err := m.SIMs().Primary()
 	if err != nil {
 		slog.Error("failed to fetch SIM", "modem", m.EquipmentIdentifier, "error", err)
@@ -229,11 +282,37 @@ func (s *Service) buildModemResponse(m *mmodem.Modem) (*ModemResponse, error) {
 			Name: registeredOperatorName,
 			Code: operatorCode,
 		},
-		SignalQuality: percent,
-		SupportsEsim:  supportsEsim,
+		SignalQuality:  percent,
+		SupportsEsim:   supportsEsim,
+		State:          m.State.String(),
+		UnlockRequired: m.UnlockRequired.String(),
 	}, nil
 }
 
+// buildLockedModemResponse describes a modem waiting for its PIN or PUK. Its
+// SIM and network interfaces are not available until it is unlocked.
+func (s *Service) buildLockedModemResponse(m *mmodem.Modem) *ModemResponse {
+	name := m.Model
+	if alias := s.cfg.FindModem(m.EquipmentIdentifier).Alias; alias != "" {
+		name = alias
+	}
+	response := &ModemResponse{
+		Manufacturer:     m.Manufacturer,
+		ID:               m.EquipmentIdentifier,
+		FirmwareRevision: m.FirmwareRevision,
+		HardwareRevision: m.HardwareRevision,
+		Name:             name,
+		Number:           m.Number,
+		Slots:            []SlotResponse{},
+		State:            m.State.String(),
+		UnlockRequired:   m.UnlockRequired.String(),
+	}
+	if m.Sim != nil {
+		response.SIM.Identifier = m.Sim.Identifier
+	}
+	return response
+}
+
 func (s *Service) buildSimSlotsResponse(m *mmodem.Modem) ([]SlotResponse, error) {
 	if len(m.SimSlots) == 0 {
 		return []SlotResponse{}, nil
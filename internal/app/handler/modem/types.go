@@ -43,4 +43,29 @@ type ModemResponse struct {
 	RegisteredOperator RegisteredOperatorResponse `json:"registeredOperator"`
 	SignalQuality      uint32                     `json:"signalQuality"`
 	SupportsEsim       bool                       `json:"supportsEsim"`
+	State              string                     `json:"state"`
+	UnlockRequired     string                     `json:"unlockRequired"`
+}
+
+type SignalMeasurementResponse struct {
+	RSSI      *float64 `json:"rssi,omitempty"`
+	RSCP      *float64 `json:"rscp,omitempty"`
+	ECIO      *float64 `json:"ecio,omitempty"`
+	RSRP      *float64 `json:"rsrp,omitempty"`
+	RSRQ      *float64 `json:"rsrq,omitempty"`
+	SNR       *float64 `json:"snr,omitempty"`
+	ErrorRate *float64 `json:"errorRate,omitempty"`
+}
+
+type SignalResponse struct {
+	Rate    uint32                     `json:"rate"`
+	Quality uint32                     `json:"quality"`
+	Gsm     *SignalMeasurementResponse `json:"gsm,omitempty"`
+	Umts    *SignalMeasurementResponse `json:"umts,omitempty"`
+	Lte     *SignalMeasurementResponse `json:"lte,omitempty"`
+	Nr5g    *SignalMeasurementResponse `json:"nr5g,omitempty"`
+}
+
+type SetupSignalRequest struct {
+	Rate *uint32 `json:"rate" validate:"required,lte=3600"`
 }
@@ -10,6 +10,7 @@ import (
 	sgp22 "github.com/damonto/euicc-go/v2"
 	"github.com/labstack/echo/v4"
 
+	"github.com/damonto/sigmo/internal/app/dispatcher"
 	"github.com/damonto/sigmo/internal/app/handler"
 	"github.com/damonto/sigmo/internal/pkg/config"
 	"github.com/damonto/sigmo/internal/pkg/lpa"
@@ -22,10 +23,10 @@ type Handler struct {
 	service *Service
 }
 
-func New(cfg *config.Config, manager *mmodem.Manager) *Handler {
+func New(cfg *config.Config, manager *mmodem.Manager, notifications *dispatcher.Dispatcher) *Handler {
 	return &Handler{
 		manager: manager,
-		service: NewService(cfg),
+		service: NewService(cfg, notifications),
 	}
 }
 
@@ -1,22 +1,25 @@
 package notification
 
 import (
-	"fmt"
+	"encoding/hex"
 	"log/slog"
 	"strconv"
 
 	sgp22 "github.com/damonto/euicc-go/v2"
+
+	"github.com/damonto/sigmo/internal/app/dispatcher"
 	"github.com/damonto/sigmo/internal/pkg/config"
 	"github.com/damonto/sigmo/internal/pkg/lpa"
 	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
 )
 
 type Service struct {
-	cfg *config.Config
+	cfg        *config.Config
+	dispatcher *dispatcher.Dispatcher
 }
 
-func NewService(cfg *config.Config) *Service {
-	return &Service{cfg: cfg}
+func NewService(cfg *config.Config, notifications *dispatcher.Dispatcher) *Service {
+	return &Service{cfg: cfg, dispatcher: notifications}
 }
 
 func (s *Service) List(modem *mmodem.Modem) ([]NotificationResponse, error) {
@@ -30,6 +33,11 @@ func (s *Service) List(modem *mmodem.Modem) ([]NotificationResponse, error) {
 			slog.Warn("failed to close LPA client", "error", cerr)
 		}
 	}()
+	eid, err := client.EID()
+	if err != nil {
+		slog.Error("failed to read EID", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
 	notifications, err := client.ListNotification()
 	if err != nil {
 		slog.Error("failed to list notifications", "modem", modem.EquipmentIdentifier, "error", err)
@@ -37,12 +45,24 @@ func (s *Service) List(modem *mmodem.Modem) ([]NotificationResponse, error) {
 	}
 	response := make([]NotificationResponse, 0, len(notifications))
 	for _, notification := range notifications {
-		response = append(response, NotificationResponse{
+		item := NotificationResponse{
 			SequenceNumber: strconv.FormatUint(uint64(notification.SequenceNumber), 10),
 			ICCID:          notification.ICCID.String(),
 			SMDP:           notification.Address,
-			Operation:      operationLabel(notification.ProfileManagementOperation),
-		})
+			Operation:      lpa.NotificationOperation(notification.ProfileManagementOperation),
+		}
+		attempt, ok, err := s.dispatcher.Status(hex.EncodeToString(eid), notification.SequenceNumber)
+		if err != nil {
+			slog.Warn("failed to read notification attempts", "modem", modem.EquipmentIdentifier, "sequence", notification.SequenceNumber, "error", err)
+		}
+		if ok {
+			item.Attempts = attempt.Attempts
+			item.LastError = attempt.LastError
+			item.LastAttemptAt = attempt.LastAttemptAt
+			item.NextAttemptAt = attempt.NextAttemptAt
+			item.Failed = attempt.Failed
+		}
+		response = append(response, item)
 	}
 	return response, nil
 }
@@ -62,6 +82,16 @@ func (s *Service) Resend(modem *mmodem.Modem, sequence sgp22.SequenceNumber) err
 		slog.Error("failed to resend notification", "modem", modem.EquipmentIdentifier, "sequence", sequence, "error", err)
 		return err
 	}
+	// The notification stays on the eUICC; the dispatcher removes it on its
+	// next run without sending it again, even if it had given up on it.
+	eid, err := client.EID()
+	if err != nil {
+		slog.Warn("failed to read EID", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil
+	}
+	if err := s.dispatcher.MarkSent(hex.EncodeToString(eid), sequence); err != nil {
+		slog.Warn("failed to record sent notification", "modem", modem.EquipmentIdentifier, "sequence", sequence, "error", err)
+	}
 	return nil
 }
 
@@ -82,18 +112,3 @@ func (s *Service) Delete(modem *mmodem.Modem, sequence sgp22.SequenceNumber) err
 	}
 	return nil
 }
-
-func operationLabel(event sgp22.NotificationEvent) string {
-	switch event {
-	case sgp22.NotificationEventInstall:
-		return "install"
-	case sgp22.NotificationEventEnable:
-		return "enable"
-	case sgp22.NotificationEventDisable:
-		return "disable"
-	case sgp22.NotificationEventDelete:
-		return "delete"
-	default:
-		return fmt.Sprint(event)
-	}
-}
@@ -1,8 +1,17 @@
 package notification
 
+import "time"
+
+// NotificationResponse is a pending notification. The delivery fields are
+// set once the background dispatcher failed to send it.
 type NotificationResponse struct {
-	SequenceNumber string `json:"sequenceNumber"`
-	ICCID          string `json:"iccid"`
-	SMDP           string `json:"smdp"`
-	Operation      string `json:"operation"`
+	SequenceNumber string    `json:"sequenceNumber"`
+	ICCID          string    `json:"iccid"`
+	SMDP           string    `json:"smdp"`
+	Operation      string    `json:"operation"`
+	Attempts       int       `json:"attempts,omitempty"`
+	LastError      string    `json:"lastError,omitempty"`
+	LastAttemptAt  time.Time `json:"lastAttemptAt,omitzero"`
+	NextAttemptAt  time.Time `json:"nextAttemptAt,omitzero"`
+	Failed         bool      `json:"failed,omitempty"`
 }
@@ -0,0 +1,97 @@
+package sim
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/damonto/sigmo/internal/app/handler"
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Handler struct {
+	handler.Handler
+	manager *mmodem.Manager
+	service *Service
+}
+
+func New(manager *mmodem.Manager) *Handler {
+	return &Handler{
+		manager: manager,
+		service: NewService(),
+	}
+}
+
+func (h *Handler) Status(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	response, err := h.service.Status(modem)
+	if err != nil {
+		return h.InternalServerError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) Unlock(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req UnlockRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	response, err := h.service.Unlock(modem, req)
+	if err != nil {
+		return h.pinError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) ChangePin(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req ChangePinRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	response, err := h.service.ChangePin(modem, req)
+	if err != nil {
+		return h.pinError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) UpdatePinLock(c echo.Context) error {
+	modem, err := h.FindModem(h.manager, c.Param("id"))
+	if err != nil {
+		return h.NotFound(c, err)
+	}
+	var req UpdatePinLockRequest
+	if err := h.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	response, err := h.service.UpdatePinLock(modem, req)
+	if err != nil {
+		return h.pinError(c, err)
+	}
+	return h.Respond(c, response)
+}
+
+func (h *Handler) pinError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, mmodem.ErrSimPukRequired):
+		return h.Conflict(c, err)
+	case errors.Is(err, mmodem.ErrIncorrectPassword),
+		errors.Is(err, errSimUnavailable),
+		errors.Is(err, errInvalidPin),
+		errors.Is(err, errInvalidPuk),
+		errors.Is(err, errEnabledRequired):
+		return h.BadRequest(c, err)
+	}
+	return h.InternalServerError(c, err)
+}
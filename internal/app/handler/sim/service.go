@@ -0,0 +1,116 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	mmodem "github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+type Service struct{}
+
+var (
+	errSimUnavailable  = errors.New("SIM not available")
+	errInvalidPin      = errors.New("PIN must be 4 to 8 digits")
+	errInvalidPuk      = errors.New("PUK must be 8 digits")
+	errEnabledRequired = errors.New("enabled is required")
+)
+
+var (
+	pinRE = regexp.MustCompile(`^[0-9]{4,8}$`)
+	pukRE = regexp.MustCompile(`^[0-9]{8}$`)
+)
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Status(modem *mmodem.Modem) (*LockResponse, error) {
+	lock, retries, err := modem.UnlockStatus()
+	if err != nil {
+		slog.Error("failed to fetch unlock status", "modem", modem.EquipmentIdentifier, "error", err)
+		return nil, err
+	}
+	response := &LockResponse{
+		State:          modem.State.String(),
+		UnlockRequired: lock.String(),
+		UnlockRetries:  make(map[string]uint32, len(retries)),
+	}
+	for l, count := range retries {
+		response.UnlockRetries[l.String()] = count
+	}
+	return response, nil
+}
+
+// Unlock sends the PIN, or with a PUK, unblocks the SIM and sets the PIN.
+func (s *Service) Unlock(modem *mmodem.Modem, req UnlockRequest) (*LockResponse, error) {
+	if modem.Sim == nil {
+		return nil, errSimUnavailable
+	}
+	if !pinRE.MatchString(req.PIN) {
+		return nil, errInvalidPin
+	}
+	if req.PUK != "" {
+		if !pukRE.MatchString(req.PUK) {
+			return nil, errInvalidPuk
+		}
+		if err := modem.Sim.SendPuk(req.PUK, req.PIN); err != nil {
+			return nil, s.codeError(modem, "failed to send PUK", mmodem.ModemLockSimPuk, err)
+		}
+	} else if err := modem.Sim.SendPin(req.PIN); err != nil {
+		return nil, s.codeError(modem, "failed to send PIN", mmodem.ModemLockSimPin, err)
+	}
+	return s.Status(modem)
+}
+
+func (s *Service) ChangePin(modem *mmodem.Modem, req ChangePinRequest) (*LockResponse, error) {
+	if modem.Sim == nil {
+		return nil, errSimUnavailable
+	}
+	if !pinRE.MatchString(req.PIN) || !pinRE.MatchString(req.NewPIN) {
+		return nil, errInvalidPin
+	}
+	if err := modem.Sim.ChangePin(req.PIN, req.NewPIN); err != nil {
+		return nil, s.codeError(modem, "failed to change PIN", mmodem.ModemLockSimPin, err)
+	}
+	return s.Status(modem)
+}
+
+func (s *Service) UpdatePinLock(modem *mmodem.Modem, req UpdatePinLockRequest) (*LockResponse, error) {
+	if modem.Sim == nil {
+		return nil, errSimUnavailable
+	}
+	if req.Enabled == nil {
+		return nil, errEnabledRequired
+	}
+	if !pinRE.MatchString(req.PIN) {
+		return nil, errInvalidPin
+	}
+	if err := modem.Sim.EnablePin(req.PIN, *req.Enabled); err != nil {
+		return nil, s.codeError(modem, "failed to update PIN lock", mmodem.ModemLockSimPin, err)
+	}
+	return s.Status(modem)
+}
+
+// codeError logs a failed PIN or PUK operation and adds the attempts left
+// for the lock to a wrong code error.
+func (s *Service) codeError(modem *mmodem.Modem, msg string, lock mmodem.ModemLock, err error) error {
+	slog.Error(msg, "modem", modem.EquipmentIdentifier, "error", err)
+	if !errors.Is(err, mmodem.ErrIncorrectPassword) {
+		return err
+	}
+	required, retries, rerr := modem.UnlockStatus()
+	if rerr != nil {
+		return err
+	}
+	if lock == mmodem.ModemLockSimPin && required == mmodem.ModemLockSimPuk {
+		// Out of PIN attempts, the SIM now wants its PUK.
+		return fmt.Errorf("%w, %d attempts remaining", mmodem.ErrSimPukRequired, retries[mmodem.ModemLockSimPuk])
+	}
+	if count, ok := retries[lock]; ok {
+		return fmt.Errorf("%w, %d attempts remaining", mmodem.ErrIncorrectPassword, count)
+	}
+	return err
+}
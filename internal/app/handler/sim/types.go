@@ -0,0 +1,22 @@
+package sim
+
+type LockResponse struct {
+	State          string            `json:"state"`
+	UnlockRequired string            `json:"unlockRequired"`
+	UnlockRetries  map[string]uint32 `json:"unlockRetries"`
+}
+
+type UnlockRequest struct {
+	PIN string `json:"pin" validate:"required"`
+	PUK string `json:"puk"`
+}
+
+type ChangePinRequest struct {
+	PIN    string `json:"pin" validate:"required"`
+	NewPIN string `json:"newPin" validate:"required"`
+}
+
+type UpdatePinLockRequest struct {
+	PIN     string `json:"pin" validate:"required"`
+	Enabled *bool  `json:"enabled" validate:"required"`
+}
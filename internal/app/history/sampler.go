@@ -0,0 +1,142 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+const (
+	defaultInterval = time.Minute
+	// defaultSamples keeps a week of samples at the default interval.
+	defaultSamples = 7 * 24 * 60
+	minInterval    = 5 * time.Second
+)
+
+// Sampler periodically records the signal and registration state of every
+// modem.
+type Sampler struct {
+	store    *Store
+	manager  *modem.Manager
+	interval time.Duration
+	samples  int
+	refresh  bool
+}
+
+func NewSampler(cfg *config.Config, store *Store, manager *modem.Manager) *Sampler {
+	s := &Sampler{
+		store:    store,
+		manager:  manager,
+		interval: cfg.History.Interval,
+		samples:  cfg.History.Samples,
+		refresh:  cfg.History.SignalRefresh,
+	}
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	s.interval = max(s.interval, minInterval)
+	if s.samples <= 0 {
+		s.samples = defaultSamples
+	}
+	return s
+}
+
+// Interval returns the time between two samples of a modem.
+func (s *Sampler) Interval() time.Duration {
+	return s.interval
+}
+
+func (s *Sampler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sampleAll(); err != nil {
+			slog.Error("failed to sample modems", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sampler) sampleAll() error {
+	modems, err := s.manager.Modems()
+	if err != nil {
+		return fmt.Errorf("listing modems: %w", err)
+	}
+	now := time.Now()
+	for _, m := range modems {
+		sample, err := s.sample(m)
+		if err != nil {
+			slog.Warn("failed to sample modem", "modem", m.EquipmentIdentifier, "error", err)
+			continue
+		}
+		sample.Time = now
+		if err := s.store.Save(m.EquipmentIdentifier, sample, s.interval*time.Duration(s.samples)); err != nil {
+			slog.Error("failed to save sample", "modem", m.EquipmentIdentifier, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *Sampler) sample(m *modem.Modem) (Sample, error) {
+	var sample Sample
+	quality, _, err := m.SignalQuality()
+	if err != nil {
+		return sample, fmt.Errorf("fetching signal quality: %w", err)
+	}
+	sample.Quality = quality
+
+	technologies, err := m.AccessTechnologies()
+	if err != nil {
+		return sample, fmt.Errorf("fetching access technologies: %w", err)
+	}
+	names := make([]string, 0, len(technologies))
+	for _, technology := range technologies {
+		names = append(names, technology.String())
+	}
+	sample.AccessTechnology = strings.Join(names, ",")
+
+	state, err := m.ThreeGPP().RegistrationState()
+	if err != nil {
+		return sample, fmt.Errorf("fetching registration state: %w", err)
+	}
+	sample.RegistrationState = state.String()
+	sample.Registered = state.Registered()
+	if sample.Registered {
+		if sample.OperatorCode, err = m.ThreeGPP().OperatorCode(); err != nil {
+			slog.Warn("failed to fetch operator code", "modem", m.EquipmentIdentifier, "error", err)
+		}
+		if sample.OperatorName, err = m.ThreeGPP().OperatorName(); err != nil {
+			slog.Warn("failed to fetch operator name", "modem", m.EquipmentIdentifier, "error", err)
+		}
+	}
+
+	// The extended values are only available while the Signal refresh is set
+	// up. It is left to the user unless signal_refresh asks to keep it on.
+	if info, err := m.Signal().Get(); err == nil {
+		if s.refresh && info.Rate == 0 {
+			if err := m.Signal().Setup(uint32(s.interval / time.Second)); err != nil {
+				slog.Warn("failed to set up signal refresh", "modem", m.EquipmentIdentifier, "error", err)
+			}
+		}
+		for _, measurement := range []*modem.SignalMeasurement{info.Nr5g, info.Lte, info.Umts, info.Gsm} {
+			if measurement == nil {
+				continue
+			}
+			sample.RSSI = measurement.RSSI
+			sample.RSRP = measurement.RSRP
+			sample.RSRQ = measurement.RSRQ
+			sample.SNR = measurement.SNR
+			break
+		}
+	}
+	return sample, nil
+}
@@ -0,0 +1,116 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var samplesBucket = []byte("samples")
+
+// Store keeps a bounded series of samples per modem on disk. Samples are keyed
+// by their timestamp, so the expired ones are found at the start of the
+// bucket.
+type Store struct {
+	db *bolt.DB
+}
+
+// Sample is the state of a modem at one point in time. RF values are taken
+// from the access technology in use and are nil when the modem does not
+// report them.
+type Sample struct {
+	Time              time.Time `json:"time"`
+	Quality           uint32    `json:"quality"`
+	AccessTechnology  string    `json:"accessTechnology"`
+	RegistrationState string    `json:"registrationState"`
+	Registered        bool      `json:"registered"`
+	OperatorCode      string    `json:"operatorCode,omitempty"`
+	OperatorName      string    `json:"operatorName,omitempty"`
+	RSSI              *float64  `json:"rssi,omitempty"`
+	RSRP              *float64  `json:"rsrp,omitempty"`
+	RSRQ              *float64  `json:"rsrq,omitempty"`
+	SNR               *float64  `json:"snr,omitempty"`
+}
+
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating history directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening history: %w", err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(samplesBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing history: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save records the sample and drops the samples of the modem older than the
+// retention window before it.
+func (s *Store) Save(modemID string, sample Sample, retention time.Duration) error {
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("encoding sample: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(samplesBucket).CreateBucketIfNotExists([]byte(modemID))
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(sampleKey(sample.Time), data); err != nil {
+			return err
+		}
+		// Keys are ordered by time, so the expired samples come first.
+		cutoff := sampleKey(sample.Time.Add(-retention))
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, cutoff) < 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Samples returns the samples of the modem between from and to, oldest first.
+func (s *Store) Samples(modemID string, from time.Time, to time.Time) ([]Sample, error) {
+	var samples []Sample
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(samplesBucket).Bucket([]byte(modemID))
+		if bucket == nil {
+			return nil
+		}
+		end := sampleKey(to)
+		c := bucket.Cursor()
+		for k, v := c.Seek(sampleKey(from)); k != nil && bytes.Compare(k, end) <= 0; k, v = c.Next() {
+			var sample Sample
+			if err := json.Unmarshal(v, &sample); err != nil {
+				return fmt.Errorf("decoding sample: %w", err)
+			}
+			samples = append(samples, sample)
+		}
+		return nil
+	})
+	return samples, err
+}
+
+func sampleKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
@@ -10,9 +10,16 @@ import (
 	"github.com/damonto/sigmo/internal/app/handler"
 )
 
-const bearerPrefix = "Bearer "
+const (
+	bearerPrefix      = "Bearer "
+	sessionContextKey = "session"
+	apiKeyContextKey  = "apiKey"
+)
 
-func Auth(store *auth.Store) echo.MiddlewareFunc {
+// Auth accepts either a session token or an API key. API keys are further
+// restricted by Scope and SessionOnly. Unless required, requests without a
+// token pass unauthenticated, but a token that is sent must be valid.
+func Auth(store *auth.Store, required bool) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			header := c.Request().Header.Get("Authorization")
@@ -23,13 +30,78 @@ func Auth(store *auth.Store) echo.MiddlewareFunc {
 			if token == "" {
 				token = strings.TrimSpace(c.QueryParam("token"))
 			}
-			if token == "" || !store.ValidateToken(token) {
-				return c.JSON(http.StatusUnauthorized, handler.HTTPError{
-					Code:    http.StatusUnauthorized,
-					Message: "missing or invalid token",
-				})
+			if token == "" && !required {
+				return next(c)
+			}
+			if session, ok := store.ValidateToken(token, c.RealIP(), c.Request().UserAgent()); ok {
+				c.Set(sessionContextKey, session)
+				return next(c)
+			}
+			if key, ok := store.ValidateAPIKey(token); ok {
+				c.Set(apiKeyContextKey, key)
+				return next(c)
+			}
+			return c.JSON(http.StatusUnauthorized, handler.HTTPError{
+				Code:    http.StatusUnauthorized,
+				Message: "missing or invalid token",
+			})
+		}
+	}
+}
+
+// Scope rejects API keys that lack the scope or, for routes with a modem ID,
+// are not allowed to use that modem. Sessions are not restricted.
+func Scope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, ok := APIKey(c)
+			if !ok {
+				return next(c)
+			}
+			if !key.Allows(scope) {
+				return forbidden(c, "api key is missing the "+scope+" scope")
+			}
+			if id := c.Param("id"); id != "" && !key.AllowsModem(id) {
+				return forbidden(c, "api key is not allowed to use this modem")
+			}
+			return next(c)
+		}
+	}
+}
+
+// SessionOnly rejects API keys, e.g. for managing sessions and API keys.
+func SessionOnly() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := APIKey(c); ok {
+				return forbidden(c, "api keys cannot access this endpoint")
 			}
 			return next(c)
 		}
 	}
 }
+
+// Session returns the session authenticated by Auth for the request.
+func Session(c echo.Context) (auth.Session, bool) {
+	session, ok := c.Get(sessionContextKey).(auth.Session)
+	return session, ok
+}
+
+// APIKey returns the API key authenticated by Auth for the request.
+func APIKey(c echo.Context) (auth.APIKey, bool) {
+	key, ok := c.Get(apiKeyContextKey).(auth.APIKey)
+	return key, ok
+}
+
+// AllowsModem reports whether the request may see the modem.
+func AllowsModem(c echo.Context, id string) bool {
+	key, ok := APIKey(c)
+	return !ok || key.AllowsModem(id)
+}
+
+func forbidden(c echo.Context, message string) error {
+	return c.JSON(http.StatusForbidden, handler.HTTPError{
+		Code:    http.StatusForbidden,
+		Message: message,
+	})
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/damonto/sigmo/internal/pkg/metrics"
+)
+
+// Metrics records every request by its route pattern, so modem IDs and other
+// parameters do not end up in label values.
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			if err := next(c); err != nil {
+				// Let the error handler write the response so the status is known.
+				c.Error(err)
+			}
+			metrics.ObserveHTTP(c.Request().Method, c.Path(), c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
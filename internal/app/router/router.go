@@ -7,92 +7,160 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/damonto/sigmo/internal/app/archive"
 	"github.com/damonto/sigmo/internal/app/auth"
+	"github.com/damonto/sigmo/internal/app/dispatcher"
+	"github.com/damonto/sigmo/internal/app/events"
 	hauth "github.com/damonto/sigmo/internal/app/handler/auth"
+	"github.com/damonto/sigmo/internal/app/handler/bearer"
+	"github.com/damonto/sigmo/internal/app/handler/call"
 	"github.com/damonto/sigmo/internal/app/handler/esim"
 	"github.com/damonto/sigmo/internal/app/handler/euicc"
+	"github.com/damonto/sigmo/internal/app/handler/event"
+	hhistory "github.com/damonto/sigmo/internal/app/handler/history"
 	"github.com/damonto/sigmo/internal/app/handler/message"
 	hmodem "github.com/damonto/sigmo/internal/app/handler/modem"
 	"github.com/damonto/sigmo/internal/app/handler/network"
 	"github.com/damonto/sigmo/internal/app/handler/notification"
+	"github.com/damonto/sigmo/internal/app/handler/sim"
 	"github.com/damonto/sigmo/internal/app/handler/ussd"
+	"github.com/damonto/sigmo/internal/app/history"
 	appmiddleware "github.com/damonto/sigmo/internal/app/middleware"
 	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/metrics"
 	"github.com/damonto/sigmo/internal/pkg/modem"
 	"github.com/damonto/sigmo/web"
 )
 
-func Register(e *echo.Echo, cfg *config.Config, manager *modem.Manager) {
+func Register(e *echo.Echo, cfg *config.Config, manager *modem.Manager, messages *archive.Store, sessions *auth.Store, hub *events.Hub, samples *history.Store, notifications *dispatcher.Dispatcher) {
 	e.Use(middleware.StaticWithConfig(middleware.StaticConfig{
 		Filesystem: http.FS(web.Root()),
 		Index:      "index.html",
 		HTML5:      true,
 		Skipper: func(c echo.Context) bool {
 			path := c.Request().URL.Path
-			return strings.HasPrefix(path, "/api/")
+			return strings.HasPrefix(path, "/api/") || path == "/metrics"
 		},
 	}))
 
 	v1 := e.Group("/api/v1")
 
-	authStore := auth.NewStore()
-	authHandler := hauth.New(cfg, authStore)
+	authHandler := hauth.New(cfg, sessions)
 	v1.GET("/auth/otp/required", authHandler.OTPRequirement)
 	v1.POST("/auth/otp", authHandler.SendOTP)
 	v1.POST("/auth/otp/verify", authHandler.VerifyOTP)
-	protected := v1.Group("")
-	if cfg.App.OTPRequired {
-		protected.Use(appmiddleware.Auth(authStore))
+	authenticate := appmiddleware.Auth(sessions, cfg.App.OTPRequired)
+	protected := v1.Group("", authenticate)
+	scope := appmiddleware.Scope
+
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()), authenticate, scope(auth.ScopeMetrics))
+
+	{
+		account := protected.Group("/auth", appmiddleware.SessionOnly())
+		account.GET("/sessions", authHandler.ListSessions)
+		account.DELETE("/sessions/current", authHandler.Logout)
+		account.DELETE("/sessions/:id", authHandler.RevokeSession)
+		account.GET("/api-keys", authHandler.ListAPIKeys)
+		account.POST("/api-keys", authHandler.CreateAPIKey)
+		account.DELETE("/api-keys/:id", authHandler.DeleteAPIKey)
+	}
+
+	{
+		// Events are filtered per API key scope inside the stream.
+		h := event.New(hub)
+		protected.GET("/events", h.Stream)
 	}
 
 	{
 		h := hmodem.New(cfg, manager)
-		protected.GET("/modems", h.List)
-		protected.GET("/modems/:id", h.Get)
-		protected.PUT("/modems/:id/sim-slots/:identifier", h.SwitchSimSlot)
-		protected.PUT("/modems/:id/msisdn", h.UpdateMSISDN)
-		protected.GET("/modems/:id/settings", h.GetSettings)
-		protected.PUT("/modems/:id/settings", h.UpdateSettings)
+		protected.GET("/modems", h.List, scope(auth.ScopeModemsRead))
+		protected.GET("/modems/:id", h.Get, scope(auth.ScopeModemsRead))
+		protected.PUT("/modems/:id/sim-slots/:identifier", h.SwitchSimSlot, scope(auth.ScopeModemsManage))
+		protected.PUT("/modems/:id/msisdn", h.UpdateMSISDN, scope(auth.ScopeModemsManage))
+		protected.GET("/modems/:id/signal", h.Signal, scope(auth.ScopeModemsRead))
+		protected.PUT("/modems/:id/signal", h.SetupSignal, scope(auth.ScopeModemsManage))
+		protected.GET("/modems/:id/settings", h.GetSettings, scope(auth.ScopeModemsRead))
+		protected.PUT("/modems/:id/settings", h.UpdateSettings, scope(auth.ScopeModemsManage))
+
+		{
+			h := sim.New(manager)
+			protected.GET("/modems/:id/sim/lock", h.Status, scope(auth.ScopeModemsRead))
+			protected.POST("/modems/:id/sim/unlock", h.Unlock, scope(auth.ScopeModemsManage))
+			protected.PUT("/modems/:id/sim/pin", h.ChangePin, scope(auth.ScopeModemsManage))
+			protected.PUT("/modems/:id/sim/pin-lock", h.UpdatePinLock, scope(auth.ScopeModemsManage))
+		}
 
 		{
-			h := message.New(manager)
-			protected.GET("/modems/:id/messages", h.List)
-			protected.GET("/modems/:id/messages/:participant", h.ListByParticipant)
-			protected.POST("/modems/:id/messages", h.Send)
-			protected.DELETE("/modems/:id/messages/:participant", h.DeleteByParticipant)
+			h := hhistory.New(manager, samples)
+			protected.GET("/modems/:id/signal/history", h.List, scope(auth.ScopeModemsRead))
+		}
+
+		{
+			h := message.New(manager, messages, hub)
+			protected.GET("/modems/:id/messages", h.List, scope(auth.ScopeMessagesRead))
+			protected.GET("/modems/:id/messages/:participant", h.ListByParticipant, scope(auth.ScopeMessagesRead))
+			protected.POST("/modems/:id/messages", h.Send, scope(auth.ScopeMessagesSend))
+			protected.DELETE("/modems/:id/messages/:participant", h.DeleteByParticipant, scope(auth.ScopeMessagesDelete))
 		}
 
 		{
 			h := ussd.New(manager)
-			protected.POST("/modems/:id/ussd", h.Execute)
+			protected.POST("/modems/:id/ussd", h.Execute, scope(auth.ScopeUSSD))
+		}
+
+		{
+			h := call.New(manager)
+			protected.GET("/modems/:id/calls", h.List, scope(auth.ScopeCalls))
+			protected.POST("/modems/:id/calls", h.Create, scope(auth.ScopeCalls))
+			protected.DELETE("/modems/:id/calls", h.HangupAll, scope(auth.ScopeCalls))
+			protected.POST("/modems/:id/calls/:call/accept", h.Accept, scope(auth.ScopeCalls))
+			protected.POST("/modems/:id/calls/:call/hangup", h.Hangup, scope(auth.ScopeCalls))
+			protected.POST("/modems/:id/calls/:call/dtmf", h.SendDtmf, scope(auth.ScopeCalls))
+			protected.DELETE("/modems/:id/calls/:call", h.Delete, scope(auth.ScopeCalls))
 		}
 
 		{
 			h := network.New(manager)
-			protected.GET("/modems/:id/networks", h.List)
-			protected.PUT("/modems/:id/networks/:operatorCode", h.Register)
+			protected.GET("/modems/:id/networks", h.List, scope(auth.ScopeNetworks))
+			protected.PUT("/modems/:id/networks/:operatorCode", h.Register, scope(auth.ScopeNetworks))
+		}
+
+		{
+			h := bearer.New(manager)
+			protected.GET("/modems/:id/bearers", h.List, scope(auth.ScopeModemsRead))
+			protected.POST("/modems/:id/bearers", h.Create, scope(auth.ScopeModemsManage))
+			protected.DELETE("/modems/:id/bearers/:bearer", h.Delete, scope(auth.ScopeModemsManage))
+			protected.PUT("/modems/:id/bearers/:bearer/connection", h.ConnectBearer, scope(auth.ScopeModemsManage))
+			protected.DELETE("/modems/:id/bearers/:bearer/connection", h.DisconnectBearer, scope(auth.ScopeModemsManage))
+			protected.PUT("/modems/:id/connection", h.Connect, scope(auth.ScopeModemsManage))
+			protected.DELETE("/modems/:id/connection", h.Disconnect, scope(auth.ScopeModemsManage))
 		}
 
 		{
 			h := euicc.New(cfg, manager)
-			protected.GET("/modems/:id/euicc", h.Get)
+			protected.GET("/modems/:id/euicc", h.Get, scope(auth.ScopeEsimRead))
+			protected.GET("/modems/:id/euicc/export", h.Export, scope(auth.ScopeEsimRead))
+			protected.GET("/modems/:id/euicc/addresses", h.Addresses, scope(auth.ScopeEsimRead))
+			protected.PUT("/modems/:id/euicc/addresses", h.UpdateAddresses, scope(auth.ScopeEsimManage))
 		}
 
 		{
-			h := esim.New(cfg, manager)
-			protected.GET("/modems/:id/esims", h.List)
-			protected.GET("/modems/:id/esims/discover", h.Discover)
-			protected.GET("/modems/:id/esims/download", h.Download)
-			protected.POST("/modems/:id/esims/:iccid/enabling", h.Enable)
-			protected.PUT("/modems/:id/esims/:iccid/nickname", h.UpdateNickname)
-			protected.DELETE("/modems/:id/esims/:iccid", h.Delete)
+			h := esim.New(cfg, manager, hub)
+			protected.GET("/modems/:id/esims", h.List, scope(auth.ScopeEsimRead))
+			protected.GET("/modems/:id/esims/discover", h.Discover, scope(auth.ScopeEsimManage))
+			protected.GET("/modems/:id/esims/download", h.Download, scope(auth.ScopeEsimManage))
+			protected.POST("/modems/:id/esims/:iccid/enabling", h.Enable, scope(auth.ScopeEsimManage))
+			protected.POST("/modems/:id/esims/:iccid/disabling", h.Disable, scope(auth.ScopeEsimManage))
+			protected.PUT("/modems/:id/esims/:iccid/nickname", h.UpdateNickname, scope(auth.ScopeEsimManage))
+			protected.DELETE("/modems/:id/esims/:iccid", h.Delete, scope(auth.ScopeEsimManage))
+			protected.DELETE("/modems/:id/esims", h.Reset, scope(auth.ScopeEsimManage))
 		}
 
 		{
-			h := notification.New(cfg, manager)
-			protected.GET("/modems/:id/notifications", h.List)
-			protected.POST("/modems/:id/notifications/:sequence/resend", h.Resend)
-			protected.DELETE("/modems/:id/notifications/:sequence", h.Delete)
+			h := notification.New(cfg, manager, notifications)
+			protected.GET("/modems/:id/notifications", h.List, scope(auth.ScopeEsimRead))
+			protected.POST("/modems/:id/notifications/:sequence/resend", h.Resend, scope(auth.ScopeEsimManage))
+			protected.DELETE("/modems/:id/notifications/:sequence", h.Delete, scope(auth.ScopeEsimManage))
 		}
 	}
 }
@@ -0,0 +1,56 @@
+package unlock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var failuresBucket = []byte("failures")
+
+// failures remembers the PINs the SIMs rejected, by ICCID, so they are never
+// entered again, not even after a restart. Only a keyed digest of each PIN is
+// kept.
+type failures struct {
+	db *bolt.DB
+}
+
+func openFailures(path string) (*failures, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening unlock failures: %w", err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(failuresBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing unlock failures: %w", err)
+	}
+	return &failures{db: db}, nil
+}
+
+func (f *failures) Close() error {
+	return f.db.Close()
+}
+
+func (f *failures) failed(iccid string, digest string) (bool, error) {
+	var failed bool
+	err := f.db.View(func(tx *bolt.Tx) error {
+		failed = string(tx.Bucket(failuresBucket).Get([]byte(iccid))) == digest
+		return nil
+	})
+	return failed, err
+}
+
+func (f *failures) record(iccid string, digest string) error {
+	return f.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(failuresBucket).Put([]byte(iccid), []byte(digest))
+	})
+}
@@ -0,0 +1,162 @@
+package unlock
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+// Unlocker enters the configured PIN of SIMs that come up locked, e.g. after
+// a power cut.
+type Unlocker struct {
+	manager  *modem.Manager
+	key      []byte
+	pins     map[string]string
+	failures *failures
+	mu       sync.Mutex
+	pending  map[string]bool
+}
+
+func New(cfg *config.Config, manager *modem.Manager) (*Unlocker, error) {
+	u := &Unlocker{
+		manager: manager,
+		pins:    make(map[string]string, len(cfg.SIMs)),
+		pending: make(map[string]bool),
+	}
+	if len(cfg.SIMs) == 0 {
+		return u, nil
+	}
+	key, err := cfg.SecretKey()
+	if err != nil {
+		return nil, err
+	}
+	u.key = key
+	for i, sim := range cfg.SIMs {
+		iccid := strings.TrimSpace(sim.ICCID)
+		if iccid == "" {
+			return nil, fmt.Errorf("sims[%d]: iccid is required", i)
+		}
+		pin, err := config.Decrypt(key, sim.PIN)
+		if err != nil {
+			return nil, fmt.Errorf("sims[%d]: pin: %w", i, err)
+		}
+		u.pins[strings.ToLower(iccid)] = pin
+	}
+	if u.failures, err = openFailures(cfg.DataPath("unlock.db")); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (u *Unlocker) Close() error {
+	if u.failures == nil {
+		return nil
+	}
+	return u.failures.Close()
+}
+
+func (u *Unlocker) Enabled() bool {
+	return len(u.pins) > 0
+}
+
+func (u *Unlocker) Run(ctx context.Context) error {
+	modems, err := u.manager.Modems()
+	if err != nil {
+		return fmt.Errorf("listing modems: %w", err)
+	}
+	for _, m := range modems {
+		go u.unlock(m)
+	}
+	unsubscribe, err := u.manager.Subscribe(func(event modem.ModemEvent) error {
+		if event.Type != modem.ModemEventRemoved && event.Modem != nil {
+			go u.unlock(event.Modem)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("subscribing to modem manager: %w", err)
+	}
+	defer unsubscribe()
+	<-ctx.Done()
+	return nil
+}
+
+// unlock enters the PIN of a SIM waiting for it. A PIN the SIM rejected once
+// is never entered again, and the last attempt is left for a person, so a
+// wrong PIN in the config cannot block the SIM.
+func (u *Unlocker) unlock(m *modem.Modem) {
+	if m.State != modem.ModemStateLocked || m.UnlockRequired != modem.ModemLockSimPin || m.Sim == nil {
+		return
+	}
+	iccid := strings.ToLower(strings.TrimSpace(m.Sim.Identifier))
+	pin, ok := u.pins[iccid]
+	if !ok {
+		if iccid == "" {
+			slog.Warn("locked SIM has no ICCID, cannot unlock it", "modem", m.EquipmentIdentifier)
+		}
+		return
+	}
+	digest := u.digest(iccid, pin)
+	failed, err := u.failures.failed(iccid, digest)
+	if err != nil {
+		slog.Error("failed to read rejected PINs", "modem", m.EquipmentIdentifier, "iccid", iccid, "error", err)
+		return
+	}
+	if failed {
+		slog.Warn("not retrying a PIN the SIM rejected", "modem", m.EquipmentIdentifier, "iccid", iccid)
+		return
+	}
+
+	u.mu.Lock()
+	if u.pending[iccid] {
+		u.mu.Unlock()
+		return
+	}
+	u.pending[iccid] = true
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		delete(u.pending, iccid)
+		u.mu.Unlock()
+	}()
+
+	lock, retries, err := m.UnlockStatus()
+	if err != nil {
+		slog.Error("failed to fetch unlock status", "modem", m.EquipmentIdentifier, "error", err)
+		return
+	}
+	if lock != modem.ModemLockSimPin {
+		return
+	}
+	if remaining, ok := retries[modem.ModemLockSimPin]; ok && remaining <= 1 {
+		slog.Warn("leaving the last PIN attempt to the user", "modem", m.EquipmentIdentifier, "iccid", iccid)
+		return
+	}
+
+	slog.Info("unlocking SIM", "modem", m.EquipmentIdentifier, "iccid", iccid)
+	if err := m.Sim.SendPin(pin); err != nil {
+		slog.Error("failed to unlock SIM", "modem", m.EquipmentIdentifier, "iccid", iccid, "error", err)
+		if errors.Is(err, modem.ErrIncorrectPassword) || errors.Is(err, modem.ErrSimPukRequired) {
+			if err := u.failures.record(iccid, digest); err != nil {
+				slog.Error("failed to record rejected PIN", "iccid", iccid, "error", err)
+			}
+		}
+		return
+	}
+	slog.Info("SIM unlocked", "modem", m.EquipmentIdentifier, "iccid", iccid)
+}
+
+func (u *Unlocker) digest(iccid string, pin string) string {
+	mac := hmac.New(sha256.New, u.key)
+	mac.Write([]byte(iccid + ":" + pin))
+	return hex.EncodeToString(mac.Sum(nil))
+}
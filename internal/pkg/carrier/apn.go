@@ -0,0 +1,32 @@
+package carrier
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+//go:embed apn.json
+var apnDataset []byte
+
+// APN holds the data connection settings of a carrier.
+type APN struct {
+	Name     string `json:"apn"`
+	IPType   string `json:"ipType,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+var apns map[string]APN
+
+func init() {
+	if err := json.Unmarshal(apnDataset, &apns); err != nil {
+		panic(err)
+	}
+}
+
+// LookupAPN returns the default APN of the carrier with the given MCCMNC.
+func LookupAPN(mccmnc string) (APN, bool) {
+	apn, ok := apns[mccmnc]
+	return apn, ok
+}
@@ -5,34 +5,146 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Config represents the application configuration
 type Config struct {
-	App      App                `toml:"app"`
-	Channels map[string]Channel `toml:"channels"`
-	Modems   map[string]Modem   `toml:"modems"`
-	Path     string             `toml:"-"`
+	App       App                `toml:"app"`
+	Channels  map[string]Channel `toml:"channels"`
+	Templates map[string]string  `toml:"templates"`
+	Rules     []Rule             `toml:"rules"`
+	Calls     Calls              `toml:"calls"`
+	APIKeys   []APIKey           `toml:"api_keys"`
+	History   History            `toml:"history"`
+	Data      Data               `toml:"data"`
+	Esim      Esim               `toml:"esim"`
+	SIMs      []SIM              `toml:"sims"`
+	Modems    map[string]Modem   `toml:"modems"`
+	Path      string             `toml:"-"`
 }
 
 type App struct {
-	Environment   string   `toml:"environment"`
-	ListenAddress string   `toml:"listen_address"`
-	AuthProviders []string `toml:"auth_providers"`
-	OTPRequired   bool     `toml:"otp_required"`
+	Environment    string   `toml:"environment"`
+	ListenAddress  string   `toml:"listen_address"`
+	AuthProviders  []string `toml:"auth_providers"`
+	OTPRequired    bool     `toml:"otp_required"`
+	DataDir        string   `toml:"data_dir"`
+	TrustedProxies []string `toml:"trusted_proxies"`
+	SecretKeyFile  string   `toml:"secret_key_file"`
 }
 
 type Channel struct {
+	Type     string `toml:"type"`
 	Endpoint string `toml:"endpoint"`
 
 	// Telegram
-	BotToken   string     `toml:"bot_token"`
-	Recipients Recipients `toml:"recipients"`
+	BotToken    string     `toml:"bot_token"`
+	Recipients  Recipients `toml:"recipients"`
+	Interactive bool       `toml:"interactive"`
 
 	// HTTP
 	Headers map[string]string `toml:"headers"`
+
+	// Email
+	From     string `toml:"from"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+	Security string `toml:"security"`
+}
+
+// Rule decides how a forwarded SMS is routed. Rules are evaluated in order and
+// the first match wins; every condition left empty matches any message.
+type Rule struct {
+	Name      string   `toml:"name"`
+	Modems    []string `toml:"modems"`
+	Sender    string   `toml:"sender"`
+	Text      string   `toml:"text"`
+	Direction string   `toml:"direction"`
+	Hours     string   `toml:"hours"`
+	Action    string   `toml:"action"`
+	Channels  []string `toml:"channels"`
+	Template  string   `toml:"template"`
+}
+
+// Calls decides which call notifications are sent and how. Empty values
+// select the defaults.
+type Calls struct {
+	Events   []string `toml:"events"`
+	Channels []string `toml:"channels"`
+	Template string   `toml:"template"`
+}
+
+// APIKey grants automation access to the API. Keys without modems may use
+// every modem.
+type APIKey struct {
+	Name   string   `toml:"name"`
+	Key    string   `toml:"key"`
+	Scopes []string `toml:"scopes"`
+	Modems []string `toml:"modems"`
+}
+
+// History controls the background sampler recording signal and registration
+// history. Zero values select the defaults.
+type History struct {
+	Interval time.Duration `toml:"interval"`
+	Samples  int           `toml:"samples"`
+	// SignalRefresh turns the Signal refresh of a modem on at the sampling
+	// interval whenever it is off, overriding a rate of 0 set through the API.
+	SignalRefresh bool `toml:"signal_refresh"`
+}
+
+// Data controls the data connection brought up after an eSIM profile is
+// enabled.
+type Data struct {
+	AutoConnect bool `toml:"auto_connect"`
+	// AllowRoaming applies to the built-in carrier APNs; configured APNs
+	// have their own setting. Unset leaves it to ModemManager, which allows
+	// roaming.
+	AllowRoaming *bool `toml:"allow_roaming"`
+	APNs         []APN `toml:"apns"`
+}
+
+// APN maps a profile, by ICCID, or a carrier, by MCCMNC, to the data
+// connection settings to use.
+type APN struct {
+	ICCID        string `toml:"iccid"`
+	MCCMNC       string `toml:"mccmnc"`
+	APN          string `toml:"apn"`
+	IPType       string `toml:"ip_type"`
+	Auth         string `toml:"auth"`
+	User         string `toml:"user"`
+	Password     string `toml:"password"`
+	AllowRoaming *bool  `toml:"allow_roaming"`
+}
+
+// Esim controls how eSIM profiles are switched and discovered, and how eUICC
+// notifications are sent.
+type Esim struct {
+	// SafeSwitch re-enables the previous profile when the new one does not
+	// register within RegistrationTimeout (default 2m).
+	SafeSwitch          bool          `toml:"safe_switch"`
+	RegistrationTimeout time.Duration `toml:"registration_timeout"`
+	// DiscoveryServers are the SM-DS addresses queried for events, in
+	// addition to the root SM-DS configured in the eUICC. Empty uses the
+	// public GSMA servers.
+	DiscoveryServers []string `toml:"discovery_servers"`
+	// NotificationInterval is how often pending eUICC notifications are sent
+	// (default 5m); a notification failing NotificationAttempts times in a
+	// row (default 10) is given up on.
+	NotificationInterval time.Duration `toml:"notification_interval"`
+	NotificationAttempts int           `toml:"notification_attempts"`
+}
+
+// SIM holds the PIN that unlocks a SIM, by ICCID. The PIN is encrypted with
+// the secret key, see Encrypt.
+type SIM struct {
+	ICCID string `toml:"iccid"`
+	PIN   string `toml:"pin"`
 }
 
 type Modem struct {
@@ -63,6 +175,26 @@ func (c *Config) IsProduction() bool {
 	return c.App.Environment == "production"
 }
 
+// DataPath returns the path of a file in the data directory. When no data
+// directory is configured, a "data" directory next to the config file is used.
+func (c *Config) DataPath(name string) string {
+	dir := c.App.DataDir
+	if dir == "" {
+		dir = filepath.Join(filepath.Dir(c.Path), "data")
+	}
+	return filepath.Join(dir, name)
+}
+
+// ChannelType returns the type of the named channel. Channels without an
+// explicit type are typed by their name, e.g. [channels.telegram].
+func (c *Config) ChannelType(name string) string {
+	channel, ok := c.Channels[name]
+	if ok && strings.TrimSpace(channel.Type) != "" {
+		return strings.ToLower(strings.TrimSpace(channel.Type))
+	}
+	return strings.ToLower(name)
+}
+
 func (c *Config) FindModem(id string) Modem {
 	if modem, ok := c.Modems[id]; ok {
 		return modem
@@ -73,6 +205,23 @@ func (c *Config) FindModem(id string) Modem {
 	}
 }
 
+// FindAPN returns the APN configured for the ICCID, falling back to the one
+// configured for the MCCMNC.
+func (c *Config) FindAPN(iccid string, mccmnc string) (APN, bool) {
+	iccid = strings.TrimSpace(iccid)
+	for _, apn := range c.Data.APNs {
+		if iccid != "" && strings.EqualFold(strings.TrimSpace(apn.ICCID), iccid) {
+			return apn, true
+		}
+	}
+	for _, apn := range c.Data.APNs {
+		if mccmnc != "" && strings.TrimSpace(apn.ICCID) == "" && strings.TrimSpace(apn.MCCMNC) == mccmnc {
+			return apn, true
+		}
+	}
+	return APN{}, false
+}
+
 func (c *Config) Save() error {
 	if c.Path == "" {
 		return errors.New("config path is required")
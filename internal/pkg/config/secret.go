@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SecretKeyEnv names the environment variable holding the key used to
+// encrypt secrets in the config file. It takes precedence over
+// app.secret_key_file.
+const SecretKeyEnv = "SIGMO_SECRET_KEY"
+
+var ErrNoSecretKey = errors.New("no secret key, set " + SecretKeyEnv + " or app.secret_key_file")
+
+// SecretKey returns the AES-256 key derived from the configured secret.
+func (c *Config) SecretKey() ([]byte, error) {
+	secret := os.Getenv(SecretKeyEnv)
+	if secret == "" && c.App.SecretKeyFile != "" {
+		data, err := os.ReadFile(c.App.SecretKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading secret key file: %w", err)
+		}
+		secret = string(data)
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil, ErrNoSecretKey
+	}
+	key := sha256.Sum256([]byte(secret))
+	return key[:], nil
+}
+
+// Encrypt seals plaintext with AES-GCM and returns it base64 encoded, nonce
+// first.
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt.
+func Decrypt(key []byte, ciphertext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ciphertext))
+	if err != nil {
+		return "", fmt.Errorf("decoding secret: %w", err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("secret is too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("secret cannot be decrypted with this key")
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,225 @@
+package lpa
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/damonto/euicc-go/bertlv"
+	"github.com/damonto/euicc-go/bertlv/primitive"
+	"github.com/damonto/sigmo/internal/pkg/euicc"
+	"github.com/damonto/sigmo/internal/pkg/metrics"
+)
+
+// Info is the decoded EUICCInfo1 and EUICCInfo2 of an eUICC (SGP.22 ES10b
+// GetEUICCInfo).
+type Info struct {
+	EID          string
+	FreeSpace    int32
+	SASUP        string
+	Certificates []string
+
+	ProfileVersion         string
+	SVN                    string
+	FirmwareVersion        string
+	JavaCardVersion        string
+	GlobalPlatformVersion  string
+	PPVersion              string
+	SASAccreditationNumber string
+	Category               string
+	InstalledApplications  int32
+	FreeVolatileMemory     int32
+	UICCCapabilities       []string
+	RSPCapabilities        []string
+	// ForbiddenProfilePolicyRules lists the PPRs the eUICC refuses, e.g. ppr1
+	// for profiles that cannot be disabled.
+	ForbiddenProfilePolicyRules []string
+	CertificationData           *CertificationData
+	VerificationCIs             []CertificateIssuer
+	SigningCIs                  []CertificateIssuer
+	// Info1 is what the eUICC sends to the SM-DP+ when a download starts.
+	Info1 Info1
+
+	// RawInfo1 and RawInfo2 are the DER encoded responses.
+	RawInfo1 []byte
+	RawInfo2 []byte
+}
+
+type Info1 struct {
+	SVN             string
+	VerificationCIs []CertificateIssuer
+	SigningCIs      []CertificateIssuer
+}
+
+type CertificationData struct {
+	PlatformLabel    string
+	DiscoveryBaseURL string
+}
+
+// CertificateIssuer is a CI public key identifier with the name of the CI it
+// belongs to, empty when unknown.
+type CertificateIssuer struct {
+	KeyID string
+	Name  string
+}
+
+// UICCCapability, RspCapability and PprIds bit names, in bit order.
+var (
+	uiccCapabilities = []string{
+		"contactlessSupport", "usimSupport", "isimSupport", "csimSupport",
+		"akaMilenage", "akaCave", "akaTuak128", "akaTuak256",
+		"rfu1", "rfu2", "gbaAuthenUsim", "gbaAuthenISim",
+		"mbmsAuthenUsim", "eapClient", "javacard", "multos",
+		"multipleUsimSupport", "multipleIsimSupport", "multipleCsimSupport", "berTlvFileSupport",
+		"dfLinkSupport", "catTp", "getIdentity", "profile-a-x25519",
+		"profile-b-p256", "suciCalculatorApi", "dns-resolution", "scp11ac",
+		"scp11c-authorization-mechanism", "s16mode", "eaka", "iotminimal",
+	}
+	rspCapabilities = []string{
+		"additionalProfile", "crlSupport", "rpmSupport", "testProfileSupport",
+		"deviceInfoExtensibilitySupport", "serviceSpecificDataSupport",
+	}
+	profilePolicyRules = []string{"pprUpdateControl", "ppr1", "ppr2"}
+	euiccCategories    = []string{"other", "basicEuicc", "mediumEuicc", "contactlessEuicc"}
+)
+
+func (l *LPA) Info() (_ *Info, err error) {
+	defer metrics.ObserveLPA("info", time.Now(), &err)
+	var info Info
+	eid, err := l.EID()
+	if err != nil {
+		return nil, err
+	}
+	info.EID = hex.EncodeToString(eid)
+
+	info1, err := l.EUICCInfo1()
+	if err != nil {
+		return nil, err
+	}
+	if info.RawInfo1, err = info1.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	info.Info1 = Info1{
+		SVN:             version(field(info1, bertlv.ContextSpecific.Primitive(2))),
+		VerificationCIs: certificateIssuers(info1.First(bertlv.ContextSpecific.Constructed(9))),
+		SigningCIs:      certificateIssuers(info1.First(bertlv.ContextSpecific.Constructed(10))),
+	}
+
+	tlv, err := l.EUICCInfo2()
+	if err != nil {
+		return nil, err
+	}
+	if info.RawInfo2, err = tlv.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	info.ProfileVersion = version(field(tlv, bertlv.ContextSpecific.Primitive(1)))
+	info.SVN = version(field(tlv, bertlv.ContextSpecific.Primitive(2)))
+	info.FirmwareVersion = version(field(tlv, bertlv.ContextSpecific.Primitive(3)))
+	info.JavaCardVersion = version(field(tlv, bertlv.ContextSpecific.Primitive(6)))
+	info.GlobalPlatformVersion = version(field(tlv, bertlv.ContextSpecific.Primitive(7)))
+	info.PPVersion = version(field(tlv, bertlv.Universal.Primitive(4)))
+	info.UICCCapabilities = bitNames(field(tlv, bertlv.ContextSpecific.Primitive(5)), uiccCapabilities)
+	info.RSPCapabilities = bitNames(field(tlv, bertlv.ContextSpecific.Primitive(8)), rspCapabilities)
+	info.ForbiddenProfilePolicyRules = bitNames(field(tlv, bertlv.ContextSpecific.Primitive(25)), profilePolicyRules)
+	if category := field(tlv, bertlv.ContextSpecific.Primitive(11)); category != nil {
+		var n int32
+		primitive.UnmarshalInt(&n).UnmarshalBinary(category)
+		info.Category = lookup(euiccCategories, n)
+	}
+
+	// SASUP
+	info.SASAccreditationNumber = string(field(tlv, bertlv.Universal.Primitive(12)))
+	info.SASUP = euicc.LookupSASUP(info.EID, info.SASAccreditationNumber)
+
+	// certificationDataObject
+	if certification := tlv.First(bertlv.ContextSpecific.Constructed(12)); certification != nil {
+		info.CertificationData = &CertificationData{}
+		if len(certification.Children) > 0 {
+			info.CertificationData.PlatformLabel = string(certification.Children[0].Value)
+		}
+		if len(certification.Children) > 1 {
+			info.CertificationData.DiscoveryBaseURL = string(certification.Children[1].Value)
+		}
+	}
+
+	// euiccCiPKIdListForVerification and euiccCiPKIdListForSigning
+	info.VerificationCIs = certificateIssuers(tlv.First(bertlv.ContextSpecific.Constructed(9)))
+	info.SigningCIs = certificateIssuers(tlv.First(bertlv.ContextSpecific.Constructed(10)))
+	for _, ci := range info.SigningCIs {
+		info.Certificates = append(info.Certificates, euicc.LookupCertificateIssuer(ci.KeyID))
+	}
+
+	// extCardResource
+	if resource := tlv.First(bertlv.ContextSpecific.Primitive(4)); resource != nil {
+		data, _ := resource.MarshalBinary()
+		data[0] = 0x30
+		if err := resource.UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+		primitive.UnmarshalInt(&info.InstalledApplications).UnmarshalBinary(field(resource, bertlv.ContextSpecific.Primitive(1)))
+		primitive.UnmarshalInt(&info.FreeSpace).UnmarshalBinary(field(resource, bertlv.ContextSpecific.Primitive(2)))
+		primitive.UnmarshalInt(&info.FreeVolatileMemory).UnmarshalBinary(field(resource, bertlv.ContextSpecific.Primitive(3)))
+	}
+	return &info, nil
+}
+
+// field returns the value of the first child with the tag, or nil when the
+// optional field is absent.
+func field(tlv *bertlv.TLV, tag bertlv.Tag) []byte {
+	if child := tlv.First(tag); child != nil {
+		return child.Value
+	}
+	return nil
+}
+
+// version formats a VersionType, three octets for major, minor and revision.
+func version(value []byte) string {
+	if len(value) != 3 {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", value[0], value[1], value[2])
+}
+
+// bitNames returns the names of the bits set in a BER bit string, whose first
+// octet is the number of unused bits. Unnamed bits are reported by index.
+func bitNames(value []byte, names []string) []string {
+	if len(value) < 2 {
+		return nil
+	}
+	bits := (len(value)-1)*8 - int(value[0])
+	var set []string
+	for i := range bits {
+		if value[1+i/8]&(0x80>>(i%8)) == 0 {
+			continue
+		}
+		if i < len(names) {
+			set = append(set, names[i])
+		} else {
+			set = append(set, fmt.Sprintf("bit%d", i))
+		}
+	}
+	return set
+}
+
+func certificateIssuers(list *bertlv.TLV) []CertificateIssuer {
+	if list == nil {
+		return nil
+	}
+	issuers := make([]CertificateIssuer, 0, len(list.Children))
+	for _, child := range list.Children {
+		issuer := CertificateIssuer{KeyID: hex.EncodeToString(child.Value)}
+		if name := euicc.LookupCertificateIssuer(issuer.KeyID); name != issuer.KeyID {
+			issuer.Name = name
+		}
+		issuers = append(issuers, issuer)
+	}
+	return issuers
+}
+
+func lookup(names []string, n int32) string {
+	if n >= 0 && int(n) < len(names) {
+		return names[n]
+	}
+	return fmt.Sprintf("unknown(%d)", n)
+}
@@ -3,23 +3,22 @@ package lpa
 import (
 	"bytes"
 	"context"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/damonto/euicc-go/apdu"
-	"github.com/damonto/euicc-go/bertlv"
-	"github.com/damonto/euicc-go/bertlv/primitive"
 	"github.com/damonto/euicc-go/driver/at"
 	"github.com/damonto/euicc-go/driver/mbim"
 	"github.com/damonto/euicc-go/driver/qmi"
 	"github.com/damonto/euicc-go/lpa"
 	sgp22 "github.com/damonto/euicc-go/v2"
 	"github.com/damonto/sigmo/internal/pkg/config"
-	"github.com/damonto/sigmo/internal/pkg/euicc"
 	"github.com/damonto/sigmo/internal/pkg/keymutex"
+	"github.com/damonto/sigmo/internal/pkg/metrics"
 	"github.com/damonto/sigmo/internal/pkg/modem"
 )
 
@@ -33,13 +32,6 @@ type LPA struct {
 	key string // EquipmentIdentifier used for global locking
 }
 
-type Info struct {
-	EID          string
-	FreeSpace    int32
-	SASUP        string
-	Certificates []string
-}
-
 var ErrNoSupportedAID = errors.New("no supported ISD-R AID found or it's not an eUICC")
 
 var AIDs = [][]byte{
@@ -51,8 +43,9 @@ var AIDs = [][]byte{
 	{0xA0, 0x00, 0x00, 0x06, 0x28, 0x10, 0x10, 0xFF, 0xFF, 0xFF, 0xFF, 0x89, 0x00, 0x00, 0x01, 0x00}, // GlocalMe
 }
 
-func New(m *modem.Modem, cfg *config.Config) (*LPA, error) {
+func New(m *modem.Modem, cfg *config.Config) (_ *LPA, err error) {
 	gmu.Lock(m.EquipmentIdentifier)
+	defer metrics.ObserveLPA("open", time.Now(), &err)
 	instance := &LPA{key: m.EquipmentIdentifier}
 	ch, err := instance.createChannel(m)
 	if err != nil {
@@ -115,49 +108,91 @@ func (l *LPA) Close() error {
 	return l.Client.Close()
 }
 
-func (l *LPA) Info() (*Info, error) {
-	var info Info
-	eid, err := l.EID()
+func (l *LPA) Delete(id sgp22.ICCID) (err error) {
+	defer metrics.ObserveLPA("delete", time.Now(), &err)
+	lastSeq, err := l.lastSequenceNumber()
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if err := l.DeleteProfile(id); err != nil {
+		return err
 	}
-	info.EID = hex.EncodeToString(eid)
 
-	tlv, err := l.EUICCInfo2()
+	deletionNotifications, err := l.ListNotification(sgp22.NotificationEventDelete)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	var errs error
+	for _, n := range deletionNotifications {
+		if n.SequenceNumber > lastSeq && bytes.Equal(n.ICCID, id) {
+			slog.Info("sending deletion notification", "sequence", n.SequenceNumber)
+			if err := l.SendNotification(n, false); err != nil {
+				errs = errors.Join(errs, err)
+			}
+		}
 	}
+	return errs
+}
 
-	// SASUP
-	info.SASUP = euicc.LookupSASUP(info.EID, string(tlv.First(bertlv.Universal.Primitive(12)).Value))
+// Enable enables the profile and asks the modem to refresh the SIM. Its
+// notification is left to the caller, which has to wait for the modem first.
+func (l *LPA) Enable(id sgp22.ICCID) (err error) {
+	defer metrics.ObserveLPA("enable", time.Now(), &err)
+	return l.EnableProfile(id, true)
+}
+
+func (l *LPA) SetNickname(id sgp22.ICCID, nickname string) (err error) {
+	defer metrics.ObserveLPA("nickname", time.Now(), &err)
+	return l.Client.SetNickname(id, nickname)
+}
 
-	// euiccCiPKIdListForSigning
-	for _, child := range tlv.First(bertlv.ContextSpecific.Constructed(10)).Children {
-		info.Certificates = append(info.Certificates, euicc.LookupCertificateIssuer(hex.EncodeToString(child.Value)))
+func (l *LPA) Disable(id sgp22.ICCID) (err error) {
+	defer metrics.ObserveLPA("disable", time.Now(), &err)
+	lastSeq, err := l.lastSequenceNumber()
+	if err != nil {
+		return err
 	}
 
-	// extResource.freeNonVolatileMemory
-	resource := tlv.First(bertlv.ContextSpecific.Primitive(4))
-	data, _ := resource.MarshalBinary()
-	data[0] = 0x30
-	if err := resource.UnmarshalBinary(data); err != nil {
-		return nil, err
+	if err := l.DisableProfile(id, true); err != nil {
+		return err
+	}
+
+	disableNotifications, err := l.ListNotification(sgp22.NotificationEventDisable)
+	if err != nil {
+		return err
+	}
+	var errs error
+	for _, n := range disableNotifications {
+		if n.SequenceNumber > lastSeq && bytes.Equal(n.ICCID, id) {
+			slog.Info("sending disable notification", "sequence", n.SequenceNumber)
+			if err := l.SendNotification(n, false); err != nil {
+				errs = errors.Join(errs, err)
+			}
+		}
 	}
-	primitive.UnmarshalInt(&info.FreeSpace).UnmarshalBinary(resource.First(bertlv.ContextSpecific.Primitive(2)).Value)
-	return &info, nil
+	return errs
 }
 
-func (l *LPA) Delete(id sgp22.ICCID) error {
-	currentNotifications, err := l.ListNotification()
+// Reset performs an eUICC memory reset: it deletes the operational and field
+// loaded test profiles and resets the default SM-DP+ address. Deletion
+// notifications raised by the eUICC for the removed profiles are sent.
+func (l *LPA) Reset() (err error) {
+	defer metrics.ObserveLPA("reset", time.Now(), &err)
+	profiles, err := l.ListProfile(nil, nil)
 	if err != nil {
 		return err
 	}
-	var lastSeq sgp22.SequenceNumber
-	for _, n := range currentNotifications {
-		lastSeq = max(n.SequenceNumber, lastSeq)
+	lastSeq, err := l.lastSequenceNumber()
+	if err != nil {
+		return err
 	}
 
-	if err := l.DeleteProfile(id); err != nil {
+	if err := l.ResetMemory(
+		sgp22.ResetOptionDeleteOperationalProfiles,
+		sgp22.ResetOptionDeleteFieldLoadedTestProfiles,
+		sgp22.ResetOptionResetDefaultSMDPAddress,
+	); err != nil {
 		return err
 	}
 
@@ -167,17 +202,36 @@ func (l *LPA) Delete(id sgp22.ICCID) error {
 	}
 	var errs error
 	for _, n := range deletionNotifications {
-		if n.SequenceNumber > lastSeq && bytes.Equal(n.ICCID, id) {
-			slog.Info("sending deletion notification", "sequence", n.SequenceNumber)
-			if err := l.SendNotification(n, false); err != nil {
-				errs = errors.Join(errs, err)
+		if n.SequenceNumber <= lastSeq {
+			continue
+		}
+		for _, profile := range profiles {
+			if bytes.Equal(n.ICCID, profile.ICCID) {
+				slog.Info("sending deletion notification", "sequence", n.SequenceNumber)
+				if err := l.SendNotification(n, false); err != nil {
+					errs = errors.Join(errs, err)
+				}
+				break
 			}
 		}
 	}
 	return errs
 }
 
-func (l *LPA) SendNotification(searchCriteria any, delete bool) error {
+func (l *LPA) lastSequenceNumber() (sgp22.SequenceNumber, error) {
+	notifications, err := l.ListNotification()
+	if err != nil {
+		return 0, err
+	}
+	var lastSeq sgp22.SequenceNumber
+	for _, n := range notifications {
+		lastSeq = max(n.SequenceNumber, lastSeq)
+	}
+	return lastSeq, nil
+}
+
+func (l *LPA) SendNotification(searchCriteria any, delete bool) (err error) {
+	defer metrics.ObserveLPA("send_notification", time.Now(), &err)
 	notifications, err := l.RetrieveNotificationList(searchCriteria)
 	if err != nil {
 		return err
@@ -185,7 +239,9 @@ func (l *LPA) SendNotification(searchCriteria any, delete bool) error {
 	var errs error
 	for _, notification := range notifications {
 		if err := l.HandleNotification(notification); err != nil {
+			// Keep the notification so that it can be sent again.
 			errs = errors.Join(errs, err)
+			continue
 		}
 		if delete {
 			if err := l.RemoveNotificationFromList(notification.Notification.SequenceNumber); err != nil {
@@ -196,7 +252,25 @@ func (l *LPA) SendNotification(searchCriteria any, delete bool) error {
 	return errs
 }
 
-func (l *LPA) Download(ctx context.Context, activationCode *lpa.ActivationCode, opts *lpa.DownloadOptions) error {
+// NotificationOperation names the profile management operation of a
+// notification.
+func NotificationOperation(event sgp22.NotificationEvent) string {
+	switch event {
+	case sgp22.NotificationEventInstall:
+		return "install"
+	case sgp22.NotificationEventEnable:
+		return "enable"
+	case sgp22.NotificationEventDisable:
+		return "disable"
+	case sgp22.NotificationEventDelete:
+		return "delete"
+	default:
+		return fmt.Sprint(event)
+	}
+}
+
+func (l *LPA) Download(ctx context.Context, activationCode *lpa.ActivationCode, opts *lpa.DownloadOptions) (err error) {
+	defer metrics.ObserveLPA("download", time.Now(), &err)
 	slog.Info("downloading profile", "activationCode", activationCode)
 	result, err := l.DownloadProfile(ctx, activationCode, opts)
 	if err != nil {
@@ -211,18 +285,49 @@ func (l *LPA) Download(ctx context.Context, activationCode *lpa.ActivationCode,
 	return nil
 }
 
-func (l *LPA) Discover(imei sgp22.IMEI) ([]*sgp22.EventEntry, error) {
-	var entries []*sgp22.EventEntry
-	addresses := []url.URL{
-		{Scheme: "https", Host: "lpa.ds.gsma.com"},
-		{Scheme: "https", Host: "lpa.live.esimdiscovery.com"},
+// DefaultDiscoveryServers are the public SM-DS queried when no server is
+// configured.
+var DefaultDiscoveryServers = []string{
+	"lpa.ds.gsma.com",
+	"lpa.live.esimdiscovery.com",
+}
+
+// Discover queries the root SM-DS of the eUICC and the given servers for
+// events. A server that fails is skipped, the error is only returned when
+// every server failed.
+func (l *LPA) Discover(imei sgp22.IMEI, servers []string) (_ []*sgp22.EventEntry, err error) {
+	defer metrics.ObserveLPA("discover", time.Now(), &err)
+	if len(servers) == 0 {
+		servers = DefaultDiscoveryServers
 	}
-	for _, address := range addresses {
+	if addresses, err := l.EUICCConfiguredAddresses(); err != nil {
+		slog.Warn("failed to read eUICC configured addresses", "error", err)
+	} else if addresses.RootSMDSAddress != "" {
+		servers = append([]string{addresses.RootSMDSAddress}, servers...)
+	}
+
+	var entries []*sgp22.EventEntry
+	var errs error
+	var succeeded bool
+	seen := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		address, err := ParseAddress(server)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if seen[address.Host] {
+			continue
+		}
+		seen[address.Host] = true
 		slog.Info("discovering profiles", "address", address.Host)
-		discovered, err := l.Discovery(&address, imei)
+		discovered, err := l.Discovery(address, imei)
 		if err != nil {
-			return nil, err
+			slog.Warn("failed to discover profiles", "address", address.Host, "error", err)
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", address.Host, err))
+			continue
 		}
+		succeeded = true
 		for _, entry := range discovered {
 			if entry == nil {
 				continue
@@ -230,5 +335,25 @@ func (l *LPA) Discover(imei sgp22.IMEI) ([]*sgp22.EventEntry, error) {
 			entries = append(entries, entry)
 		}
 	}
+	if !succeeded && errs != nil {
+		return nil, errs
+	}
 	return entries, nil
 }
+
+// ParseAddress parses an SM-DP+ or SM-DS address given as a host or an HTTPS
+// URL. Only the host is kept, the ES9+ and ES11 paths are fixed.
+func ParseAddress(raw string) (*url.URL, error) {
+	address := strings.TrimSpace(raw)
+	if address == "" {
+		return nil, errors.New("address is required")
+	}
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+	parsed, err := url.Parse(address)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid address %q", raw)
+	}
+	return &url.URL{Scheme: "https", Host: parsed.Host}, nil
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "sigmo"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	smsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sms_received_total",
+		Help:      "SMS received, by modem.",
+	}, []string{"modem"})
+	smsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sms_sent_total",
+		Help:      "SMS sent, by modem.",
+	}, []string{"modem"})
+	smsSendFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sms_send_failures_total",
+		Help:      "SMS that could not be sent, by modem.",
+	}, []string{"modem"})
+	smsForwarded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sms_forwarded_total",
+		Help:      "SMS forwarded, by notification channel.",
+	}, []string{"channel"})
+	smsForwardFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sms_forward_failures_total",
+		Help:      "SMS that could not be forwarded, by notification channel.",
+	}, []string{"channel"})
+	lpaDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "lpa_operation_duration_seconds",
+		Help:      "Duration of LPA operations on the eUICC.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"operation"})
+	lpaFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "lpa_operation_failures_total",
+		Help:      "Failed LPA operations on the eUICC.",
+	}, []string{"operation"})
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests, by method, route and status code.",
+	}, []string{"method", "route", "code"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		smsReceived,
+		smsSent,
+		smsSendFailures,
+		smsForwarded,
+		smsForwardFailures,
+		lpaDuration,
+		lpaFailures,
+		httpRequests,
+		httpDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// Register adds a collector, e.g. the modem collector, to the registry.
+func Register(collector prometheus.Collector) error {
+	return registry.Register(collector)
+}
+
+func SMSReceived(modem string) {
+	smsReceived.WithLabelValues(modem).Inc()
+}
+
+func SMSSent(modem string, err error) {
+	if err != nil {
+		smsSendFailures.WithLabelValues(modem).Inc()
+		return
+	}
+	smsSent.WithLabelValues(modem).Inc()
+}
+
+func SMSForwarded(channel string, err error) {
+	if err != nil {
+		smsForwardFailures.WithLabelValues(channel).Inc()
+		return
+	}
+	smsForwarded.WithLabelValues(channel).Inc()
+}
+
+// ObserveLPA records an LPA operation that started at start. It is meant to
+// be deferred with a pointer to the named error result.
+func ObserveLPA(operation string, start time.Time, err *error) {
+	lpaDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil && *err != nil {
+		lpaFailures.WithLabelValues(operation).Inc()
+	}
+}
+
+func ObserveHTTP(method string, route string, code int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
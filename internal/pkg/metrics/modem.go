@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/modem"
+)
+
+var (
+	modemsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "modems"),
+		"Number of modems known to ModemManager.",
+		nil, nil,
+	)
+	modemInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "modem", "info"),
+		"Modem details, always 1.",
+		[]string{"modem", "name", "manufacturer", "model", "firmware"}, nil,
+	)
+	modemSignalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "modem", "signal_quality_percent"),
+		"Signal quality reported by ModemManager.",
+		[]string{"modem"}, nil,
+	)
+	modemRegisteredDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "modem", "registered"),
+		"Whether the modem is registered to a home or roaming network.",
+		[]string{"modem"}, nil,
+	)
+	modemRegistrationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "modem", "registration_state"),
+		"Current 3GPP registration state, always 1.",
+		[]string{"modem", "state"}, nil,
+	)
+	modemAccessTechnologyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "modem", "access_technology"),
+		"Access technologies currently in use, always 1.",
+		[]string{"modem", "technology"}, nil,
+	)
+	modemSimPresentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "modem", "sim_present"),
+		"Whether a SIM is present in the primary slot.",
+		[]string{"modem"}, nil,
+	)
+)
+
+// ModemCollector reads the modem state from ModemManager on every scrape.
+type ModemCollector struct {
+	cfg     *config.Config
+	manager *modem.Manager
+}
+
+func NewModemCollector(cfg *config.Config, manager *modem.Manager) *ModemCollector {
+	return &ModemCollector{cfg: cfg, manager: manager}
+}
+
+func (c *ModemCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- modemsDesc
+	ch <- modemInfoDesc
+	ch <- modemSignalDesc
+	ch <- modemRegisteredDesc
+	ch <- modemRegistrationDesc
+	ch <- modemAccessTechnologyDesc
+	ch <- modemSimPresentDesc
+}
+
+func (c *ModemCollector) Collect(ch chan<- prometheus.Metric) {
+	modems, err := c.manager.Modems()
+	if err != nil {
+		slog.Error("failed to list modems", "error", err)
+		ch <- prometheus.NewInvalidMetric(modemsDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(modemsDesc, prometheus.GaugeValue, float64(len(modems)))
+	for _, m := range modems {
+		c.collectModem(ch, m)
+	}
+}
+
+func (c *ModemCollector) collectModem(ch chan<- prometheus.Metric, m *modem.Modem) {
+	id := m.EquipmentIdentifier
+	name := c.cfg.FindModem(id).Alias
+	if name == "" {
+		name = m.Model
+	}
+	ch <- prometheus.MustNewConstMetric(modemInfoDesc, prometheus.GaugeValue, 1, id, name, m.Manufacturer, m.Model, m.FirmwareRevision)
+	ch <- prometheus.MustNewConstMetric(modemSimPresentDesc, prometheus.GaugeValue, boolValue(m.Sim != nil && m.Sim.Path != "/" && m.Sim.Identifier != ""), id)
+
+	if percent, _, err := m.SignalQuality(); err != nil {
+		slog.Warn("failed to fetch signal quality", "modem", id, "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(modemSignalDesc, prometheus.GaugeValue, float64(percent), id)
+	}
+
+	if state, err := m.ThreeGPP().RegistrationState(); err != nil {
+		slog.Warn("failed to fetch registration state", "modem", id, "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(modemRegisteredDesc, prometheus.GaugeValue, boolValue(state.Registered()), id)
+		ch <- prometheus.MustNewConstMetric(modemRegistrationDesc, prometheus.GaugeValue, 1, id, state.String())
+	}
+
+	if technologies, err := m.AccessTechnologies(); err != nil {
+		slog.Warn("failed to fetch access technologies", "modem", id, "error", err)
+	} else {
+		for _, technology := range technologies {
+			ch <- prometheus.MustNewConstMetric(modemAccessTechnologyDesc, prometheus.GaugeValue, 1, id, technology.String())
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -1,5 +1,7 @@
 package modem
 
+const Modem3GPPUSSDInterface = Modem3GPPInterface + ".Ussd"
+
 type USSD struct {
 	modem *Modem
 }
@@ -0,0 +1,292 @@
+package modem
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	ModemBearerInterface = ModemManagerInterface + ".Bearer"
+	ModemSimpleInterface = ModemInterface + ".Simple"
+)
+
+var (
+	ErrBearerNotFound = errors.New("bearer not found")
+	ErrInvalidIPType  = errors.New("invalid ip type")
+	ErrInvalidAuth    = errors.New("invalid auth method")
+)
+
+type Bearers struct {
+	modem *Modem
+}
+
+func (m *Modem) Bearers() *Bearers {
+	return &Bearers{modem: m}
+}
+
+// BearerProperties are the settings used to connect a bearer. Empty values
+// are left to the modem, a nil AllowRoaming to ModemManager, which allows
+// roaming.
+type BearerProperties struct {
+	APN          string
+	IPType       BearerIPFamily
+	AllowedAuth  BearerAllowedAuth
+	User         string
+	Password     string
+	AllowRoaming *bool
+}
+
+type BearerIPConfig struct {
+	Method  BearerIPMethod
+	Address string
+	Prefix  uint32
+	Gateway string
+	DNS     []string
+	MTU     uint32
+}
+
+type BearerStats struct {
+	Duration       uint32
+	RxBytes        uint64
+	TxBytes        uint64
+	Attempts       uint32
+	FailedAttempts uint32
+	TotalDuration  uint32
+	TotalRxBytes   uint64
+	TotalTxBytes   uint64
+}
+
+type Bearer struct {
+	objectPath dbus.ObjectPath
+	dbusObject dbus.BusObject
+	Interface  string
+	Connected  bool
+	Suspended  bool
+	Properties BearerProperties
+	IPv4Config *BearerIPConfig
+	IPv6Config *BearerIPConfig
+	Stats      BearerStats
+}
+
+func (b *Bearer) Path() dbus.ObjectPath {
+	return b.objectPath
+}
+
+// ID returns the index ModemManager gives the bearer, e.g. "3" for
+// /org/freedesktop/ModemManager1/Bearer/3.
+func (b *Bearer) ID() string {
+	return path.Base(string(b.objectPath))
+}
+
+func (b *Bearer) Connect() error {
+	return b.dbusObject.Call(ModemBearerInterface+".Connect", 0).Err
+}
+
+func (b *Bearer) Disconnect() error {
+	return b.dbusObject.Call(ModemBearerInterface+".Disconnect", 0).Err
+}
+
+func (bs *Bearers) List() ([]*Bearer, error) {
+	var paths []dbus.ObjectPath
+	if err := bs.modem.dbusObject.Call(ModemInterface+".ListBearers", 0).Store(&paths); err != nil {
+		return nil, err
+	}
+	bearers := make([]*Bearer, 0, len(paths))
+	for _, p := range paths {
+		bearer, err := bs.Retrieve(p)
+		if err != nil {
+			return nil, err
+		}
+		bearers = append(bearers, bearer)
+	}
+	return bearers, nil
+}
+
+// Find returns the bearer of this modem with the given ID.
+func (bs *Bearers) Find(id string) (*Bearer, error) {
+	var paths []dbus.ObjectPath
+	if err := bs.modem.dbusObject.Call(ModemInterface+".ListBearers", 0).Store(&paths); err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(paths, func(p dbus.ObjectPath) bool {
+		return path.Base(string(p)) == id
+	})
+	if index < 0 {
+		return nil, ErrBearerNotFound
+	}
+	return bs.Retrieve(paths[index])
+}
+
+func (bs *Bearers) Retrieve(objectPath dbus.ObjectPath) (*Bearer, error) {
+	dbusObject, err := systemBusObject(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	var properties map[string]dbus.Variant
+	if err := dbusObject.Call("org.freedesktop.DBus.Properties.GetAll", 0, ModemBearerInterface).Store(&properties); err != nil {
+		return nil, err
+	}
+	bearer := &Bearer{objectPath: objectPath, dbusObject: dbusObject}
+	bearer.Interface, _ = properties["Interface"].Value().(string)
+	bearer.Connected, _ = properties["Connected"].Value().(bool)
+	bearer.Suspended, _ = properties["Suspended"].Value().(bool)
+	if values, ok := properties["Properties"].Value().(map[string]dbus.Variant); ok {
+		bearer.Properties = bearerProperties(values)
+	}
+	if values, ok := properties["Ip4Config"].Value().(map[string]dbus.Variant); ok {
+		bearer.IPv4Config = bearerIPConfig(values)
+	}
+	if values, ok := properties["Ip6Config"].Value().(map[string]dbus.Variant); ok {
+		bearer.IPv6Config = bearerIPConfig(values)
+	}
+	if values, ok := properties["Stats"].Value().(map[string]dbus.Variant); ok {
+		bearer.Stats = bearerStats(values)
+	}
+	return bearer, nil
+}
+
+func (bs *Bearers) Create(properties BearerProperties) (*Bearer, error) {
+	var objectPath dbus.ObjectPath
+	if err := bs.modem.dbusObject.Call(ModemInterface+".CreateBearer", 0, properties.dict()).Store(&objectPath); err != nil {
+		return nil, err
+	}
+	return bs.Retrieve(objectPath)
+}
+
+func (bs *Bearers) Delete(objectPath dbus.ObjectPath) error {
+	return bs.modem.dbusObject.Call(ModemInterface+".DeleteBearer", 0, objectPath).Err
+}
+
+// Connect brings up a data connection with the given properties, creating or
+// reusing a bearer as needed, and returns the connected bearer.
+func (m *Modem) Connect(properties BearerProperties) (*Bearer, error) {
+	var objectPath dbus.ObjectPath
+	if err := m.dbusObject.Call(ModemSimpleInterface+".Connect", 0, properties.dict()).Store(&objectPath); err != nil {
+		return nil, err
+	}
+	return m.Bearers().Retrieve(objectPath)
+}
+
+// Disconnect tears down the data connection of the bearer, or of every bearer
+// when the path is empty.
+func (m *Modem) Disconnect(objectPath dbus.ObjectPath) error {
+	if objectPath == "" {
+		objectPath = "/"
+	}
+	return m.dbusObject.Call(ModemSimpleInterface+".Disconnect", 0, objectPath).Err
+}
+
+// ParseBearerIPFamily parses ipv4, ipv6 or ipv4v6. An empty string leaves the
+// choice to the modem.
+func ParseBearerIPFamily(s string) (BearerIPFamily, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return BearerIPFamilyNone, nil
+	case "ipv4":
+		return BearerIPFamilyIPv4, nil
+	case "ipv6":
+		return BearerIPFamilyIPv6, nil
+	case "ipv4v6":
+		return BearerIPFamilyIPv4v6, nil
+	default:
+		return BearerIPFamilyNone, fmt.Errorf("%w: %s", ErrInvalidIPType, s)
+	}
+}
+
+// ParseBearerAllowedAuth parses an authentication method such as pap or
+// chap. An empty string leaves the choice to the modem.
+func ParseBearerAllowedAuth(s string) (BearerAllowedAuth, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return BearerAllowedAuthUnknown, nil
+	}
+	for _, auth := range []BearerAllowedAuth{
+		BearerAllowedAuthNone,
+		BearerAllowedAuthPap,
+		BearerAllowedAuthChap,
+		BearerAllowedAuthMschap,
+		BearerAllowedAuthMschapv2,
+		BearerAllowedAuthEap,
+	} {
+		if auth.String() == s {
+			return auth, nil
+		}
+	}
+	return BearerAllowedAuthUnknown, fmt.Errorf("%w: %s", ErrInvalidAuth, s)
+}
+
+func (p BearerProperties) dict() map[string]dbus.Variant {
+	dict := make(map[string]dbus.Variant)
+	if p.AllowRoaming != nil {
+		dict["allow-roaming"] = dbus.MakeVariant(*p.AllowRoaming)
+	}
+	if p.APN != "" {
+		dict["apn"] = dbus.MakeVariant(p.APN)
+	}
+	if p.IPType != BearerIPFamilyNone {
+		dict["ip-type"] = dbus.MakeVariant(uint32(p.IPType))
+	}
+	if p.AllowedAuth != BearerAllowedAuthUnknown {
+		dict["allowed-auth"] = dbus.MakeVariant(uint32(p.AllowedAuth))
+	}
+	if p.User != "" {
+		dict["user"] = dbus.MakeVariant(p.User)
+	}
+	if p.Password != "" {
+		dict["password"] = dbus.MakeVariant(p.Password)
+	}
+	return dict
+}
+
+func bearerProperties(values map[string]dbus.Variant) BearerProperties {
+	var p BearerProperties
+	p.APN, _ = values["apn"].Value().(string)
+	if v, ok := values["ip-type"].Value().(uint32); ok {
+		p.IPType = BearerIPFamily(v)
+	}
+	if v, ok := values["allowed-auth"].Value().(uint32); ok {
+		p.AllowedAuth = BearerAllowedAuth(v)
+	}
+	p.User, _ = values["user"].Value().(string)
+	p.Password, _ = values["password"].Value().(string)
+	if v, ok := values["allow-roaming"].Value().(bool); ok {
+		p.AllowRoaming = &v
+	}
+	return p
+}
+
+func bearerIPConfig(values map[string]dbus.Variant) *BearerIPConfig {
+	method, ok := values["method"].Value().(uint32)
+	if !ok || BearerIPMethod(method) == BearerIPMethodUnknown {
+		return nil
+	}
+	config := &BearerIPConfig{Method: BearerIPMethod(method)}
+	config.Address, _ = values["address"].Value().(string)
+	config.Prefix, _ = values["prefix"].Value().(uint32)
+	config.Gateway, _ = values["gateway"].Value().(string)
+	config.MTU, _ = values["mtu"].Value().(uint32)
+	for _, key := range []string{"dns1", "dns2", "dns3"} {
+		if dns, _ := values[key].Value().(string); dns != "" {
+			config.DNS = append(config.DNS, dns)
+		}
+	}
+	return config
+}
+
+func bearerStats(values map[string]dbus.Variant) BearerStats {
+	var s BearerStats
+	s.Duration, _ = values["duration"].Value().(uint32)
+	s.RxBytes, _ = values["rx-bytes"].Value().(uint64)
+	s.TxBytes, _ = values["tx-bytes"].Value().(uint64)
+	s.Attempts, _ = values["attempts"].Value().(uint32)
+	s.FailedAttempts, _ = values["failed-attempts"].Value().(uint32)
+	s.TotalDuration, _ = values["total-duration"].Value().(uint32)
+	s.TotalRxBytes, _ = values["total-rx-bytes"].Value().(uint64)
+	s.TotalTxBytes, _ = values["total-tx-bytes"].Value().(uint64)
+	return s
+}
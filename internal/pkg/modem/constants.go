@@ -18,6 +18,37 @@ const (
 	ModemStateConnected                           // One or more packet data bearers is active and connected.
 )
 
+func (m ModemState) String() string {
+	switch m {
+	case ModemStateFailed:
+		return "Failed"
+	case ModemStateInitializing:
+		return "Initializing"
+	case ModemStateLocked:
+		return "Locked"
+	case ModemStateDisabled:
+		return "Disabled"
+	case ModemStateDisabling:
+		return "Disabling"
+	case ModemStateEnabling:
+		return "Enabling"
+	case ModemStateEnabled:
+		return "Enabled"
+	case ModemStateSearching:
+		return "Searching"
+	case ModemStateRegistered:
+		return "Registered"
+	case ModemStateDisconnecting:
+		return "Disconnecting"
+	case ModemStateConnecting:
+		return "Connecting"
+	case ModemStateConnected:
+		return "Connected"
+	default:
+		return "Unknown"
+	}
+}
+
 type ModemPortType uint32
 
 const (
@@ -61,6 +92,93 @@ func (s SMSState) String() string {
 	}
 }
 
+// SMSDeliveryState is the status of an outgoing message reported by the SMSC
+// in a delivery report.
+type SMSDeliveryState uint32
+
+const (
+	SMSDeliveryStateCompletedReceived                    SMSDeliveryState = 0x00  // Delivery completed, message received by the SME.
+	SMSDeliveryStateCompletedForwardedUnconfirmed        SMSDeliveryState = 0x01  // Forwarded by the SC to the SME but the SC is unable to confirm delivery.
+	SMSDeliveryStateCompletedReplacedBySc                SMSDeliveryState = 0x02  // Message replaced by the SC.
+	SMSDeliveryStateTemporaryErrorCongestion             SMSDeliveryState = 0x20  // Temporary error, congestion.
+	SMSDeliveryStateTemporaryErrorSmeBusy                SMSDeliveryState = 0x21  // Temporary error, SME busy.
+	SMSDeliveryStateTemporaryErrorNoResponseFromSme      SMSDeliveryState = 0x22  // Temporary error, no response from the SME.
+	SMSDeliveryStateTemporaryErrorServiceRejected        SMSDeliveryState = 0x23  // Temporary error, service rejected.
+	SMSDeliveryStateTemporaryErrorQosNotAvailable        SMSDeliveryState = 0x24  // Temporary error, QoS not available.
+	SMSDeliveryStateTemporaryErrorInSme                  SMSDeliveryState = 0x25  // Temporary error in the SME.
+	SMSDeliveryStateErrorRemoteProcedure                 SMSDeliveryState = 0x40  // Permanent remote procedure error.
+	SMSDeliveryStateErrorIncompatibleDestination         SMSDeliveryState = 0x41  // Permanent error, incompatible destination.
+	SMSDeliveryStateErrorConnectionRejected              SMSDeliveryState = 0x42  // Permanent error, connection rejected by the SME.
+	SMSDeliveryStateErrorNotObtainable                   SMSDeliveryState = 0x43  // Permanent error, not obtainable.
+	SMSDeliveryStateErrorQosNotAvailable                 SMSDeliveryState = 0x44  // Permanent error, QoS not available.
+	SMSDeliveryStateErrorNoInterworkingAvailable         SMSDeliveryState = 0x45  // Permanent error, no interworking available.
+	SMSDeliveryStateErrorValidityPeriodExpired           SMSDeliveryState = 0x46  // Permanent error, message validity period expired.
+	SMSDeliveryStateErrorDeletedByOriginatingSme         SMSDeliveryState = 0x47  // Permanent error, deleted by the originating SME.
+	SMSDeliveryStateErrorDeletedByScAdministration       SMSDeliveryState = 0x48  // Permanent error, deleted by the SC administration.
+	SMSDeliveryStateErrorMessageDoesNotExist             SMSDeliveryState = 0x49  // Permanent error, message does no longer exist.
+	SMSDeliveryStateTemporaryFatalErrorCongestion        SMSDeliveryState = 0x60  // SC gave up retrying, congestion.
+	SMSDeliveryStateTemporaryFatalErrorSmeBusy           SMSDeliveryState = 0x61  // SC gave up retrying, SME busy.
+	SMSDeliveryStateTemporaryFatalErrorNoResponseFromSme SMSDeliveryState = 0x62  // SC gave up retrying, no response from the SME.
+	SMSDeliveryStateTemporaryFatalErrorServiceRejected   SMSDeliveryState = 0x63  // SC gave up retrying, service rejected.
+	SMSDeliveryStateTemporaryFatalErrorQosNotAvailable   SMSDeliveryState = 0x64  // SC gave up retrying, QoS not available.
+	SMSDeliveryStateTemporaryFatalErrorInSme             SMSDeliveryState = 0x65  // SC gave up retrying, error in the SME.
+	SMSDeliveryStateUnknown                              SMSDeliveryState = 0x100 // Unknown state, e.g. no report received yet.
+)
+
+func (s SMSDeliveryState) String() string {
+	switch s {
+	case SMSDeliveryStateCompletedReceived:
+		return "Received"
+	case SMSDeliveryStateCompletedForwardedUnconfirmed:
+		return "Forwarded Unconfirmed"
+	case SMSDeliveryStateCompletedReplacedBySc:
+		return "Replaced By SC"
+	case SMSDeliveryStateTemporaryErrorCongestion, SMSDeliveryStateTemporaryFatalErrorCongestion:
+		return "Congestion"
+	case SMSDeliveryStateTemporaryErrorSmeBusy, SMSDeliveryStateTemporaryFatalErrorSmeBusy:
+		return "Recipient Busy"
+	case SMSDeliveryStateTemporaryErrorNoResponseFromSme, SMSDeliveryStateTemporaryFatalErrorNoResponseFromSme:
+		return "No Response From Recipient"
+	case SMSDeliveryStateTemporaryErrorServiceRejected, SMSDeliveryStateTemporaryFatalErrorServiceRejected:
+		return "Service Rejected"
+	case SMSDeliveryStateTemporaryErrorQosNotAvailable, SMSDeliveryStateTemporaryFatalErrorQosNotAvailable, SMSDeliveryStateErrorQosNotAvailable:
+		return "QoS Not Available"
+	case SMSDeliveryStateTemporaryErrorInSme, SMSDeliveryStateTemporaryFatalErrorInSme:
+		return "Error In Recipient"
+	case SMSDeliveryStateErrorRemoteProcedure:
+		return "Remote Procedure Error"
+	case SMSDeliveryStateErrorIncompatibleDestination:
+		return "Incompatible Destination"
+	case SMSDeliveryStateErrorConnectionRejected:
+		return "Connection Rejected"
+	case SMSDeliveryStateErrorNotObtainable:
+		return "Not Obtainable"
+	case SMSDeliveryStateErrorNoInterworkingAvailable:
+		return "No Interworking Available"
+	case SMSDeliveryStateErrorValidityPeriodExpired:
+		return "Validity Period Expired"
+	case SMSDeliveryStateErrorDeletedByOriginatingSme:
+		return "Deleted By Sender"
+	case SMSDeliveryStateErrorDeletedByScAdministration:
+		return "Deleted By SC Administration"
+	case SMSDeliveryStateErrorMessageDoesNotExist:
+		return "Message Does Not Exist"
+	default:
+		return "Unknown"
+	}
+}
+
+// Delivered reports whether the SMSC completed the delivery.
+func (s SMSDeliveryState) Delivered() bool {
+	return s < SMSDeliveryStateTemporaryErrorCongestion
+}
+
+// Failed reports whether the SMSC gave up delivering the message. Temporary
+// errors (0x20-0x3f) are still being retried by the SMSC.
+func (s SMSDeliveryState) Failed() bool {
+	return s >= SMSDeliveryStateErrorRemoteProcedure && s < SMSDeliveryStateUnknown
+}
+
 type Modem3gppRegistrationState uint32
 
 const (
@@ -106,6 +224,22 @@ func (m Modem3gppRegistrationState) String() string {
 	}
 }
 
+// Registered reports whether the modem is registered to a home or roaming
+// network.
+func (m Modem3gppRegistrationState) Registered() bool {
+	switch m {
+	case Modem3gppRegistrationStateHome,
+		Modem3gppRegistrationStateRoaming,
+		Modem3gppRegistrationStateHomeSmsOnly,
+		Modem3gppRegistrationStateRoamingSmsOnly,
+		Modem3gppRegistrationStateHomeCsfbNotPreferred,
+		Modem3gppRegistrationStateRoamingCsfbNotPreferred:
+		return true
+	default:
+		return false
+	}
+}
+
 type Modem3gppUssdSessionState uint32
 
 const (
@@ -246,3 +380,242 @@ func (m Modem3gppNetworkAvailability) String() string {
 		return "Undefined"
 	}
 }
+
+type BearerIPFamily uint32
+
+const (
+	BearerIPFamilyNone   BearerIPFamily = 0          // None or unknown.
+	BearerIPFamilyIPv4   BearerIPFamily = 1 << 0     // IPv4.
+	BearerIPFamilyIPv6   BearerIPFamily = 1 << 1     // IPv6.
+	BearerIPFamilyIPv4v6 BearerIPFamily = 1 << 2     // IPv4 and IPv6.
+	BearerIPFamilyNonIP  BearerIPFamily = 1 << 3     // Non-IP, e.g. for NB-IoT.
+	BearerIPFamilyAny    BearerIPFamily = 0xFFFFFFF7 // Any IP family.
+)
+
+func (f BearerIPFamily) String() string {
+	switch f {
+	case BearerIPFamilyNone:
+		return "none"
+	case BearerIPFamilyIPv4:
+		return "ipv4"
+	case BearerIPFamilyIPv6:
+		return "ipv6"
+	case BearerIPFamilyIPv4v6:
+		return "ipv4v6"
+	case BearerIPFamilyNonIP:
+		return "non-ip"
+	case BearerIPFamilyAny:
+		return "any"
+	default:
+		return "unknown"
+	}
+}
+
+type BearerAllowedAuth uint32
+
+const (
+	BearerAllowedAuthUnknown  BearerAllowedAuth = 0      // Unknown, the modem picks.
+	BearerAllowedAuthNone     BearerAllowedAuth = 1 << 0 // No authentication.
+	BearerAllowedAuthPap      BearerAllowedAuth = 1 << 1 // PAP.
+	BearerAllowedAuthChap     BearerAllowedAuth = 1 << 2 // CHAP.
+	BearerAllowedAuthMschap   BearerAllowedAuth = 1 << 3 // MS-CHAP.
+	BearerAllowedAuthMschapv2 BearerAllowedAuth = 1 << 4 // MS-CHAP v2.
+	BearerAllowedAuthEap      BearerAllowedAuth = 1 << 5 // EAP.
+)
+
+func (a BearerAllowedAuth) String() string {
+	switch a {
+	case BearerAllowedAuthUnknown:
+		return "unknown"
+	case BearerAllowedAuthNone:
+		return "none"
+	case BearerAllowedAuthPap:
+		return "pap"
+	case BearerAllowedAuthChap:
+		return "chap"
+	case BearerAllowedAuthMschap:
+		return "mschap"
+	case BearerAllowedAuthMschapv2:
+		return "mschapv2"
+	case BearerAllowedAuthEap:
+		return "eap"
+	default:
+		return "multiple"
+	}
+}
+
+type BearerIPMethod uint32
+
+const (
+	BearerIPMethodUnknown BearerIPMethod = iota // Unknown method.
+	BearerIPMethodPPP                           // Use PPP to get IP addresses and DNS information.
+	BearerIPMethodStatic                        // Use the provided static IP configuration.
+	BearerIPMethodDHCP                          // Run DHCP on the data interface.
+)
+
+func (m BearerIPMethod) String() string {
+	switch m {
+	case BearerIPMethodPPP:
+		return "ppp"
+	case BearerIPMethodStatic:
+		return "static"
+	case BearerIPMethodDHCP:
+		return "dhcp"
+	default:
+		return "unknown"
+	}
+}
+
+type CallState int32
+
+const (
+	CallStateUnknown    CallState = iota // Default state for a new outgoing call.
+	CallStateDialing                     // Outgoing call started. Wait for free channel.
+	CallStateRingingOut                  // Outgoing call attached to GSM network, waiting for an answer.
+	CallStateRingingIn                   // Incoming call is waiting for an answer.
+	CallStateActive                      // Call is active between two peers.
+	CallStateHeld                        // Held call (by +CHLD AT command).
+	CallStateWaiting                     // Waiting call (by +CCWA AT command).
+	CallStateTerminated                  // Call is terminated.
+)
+
+func (s CallState) String() string {
+	switch s {
+	case CallStateDialing:
+		return "Dialing"
+	case CallStateRingingOut:
+		return "Ringing Out"
+	case CallStateRingingIn:
+		return "Ringing In"
+	case CallStateActive:
+		return "Active"
+	case CallStateHeld:
+		return "Held"
+	case CallStateWaiting:
+		return "Waiting"
+	case CallStateTerminated:
+		return "Terminated"
+	default:
+		return "Unknown"
+	}
+}
+
+type CallStateReason int32
+
+const (
+	CallStateReasonUnknown          CallStateReason = iota // Default value for a new outgoing call.
+	CallStateReasonOutgoingStarted                         // Outgoing call is started.
+	CallStateReasonIncomingNew                             // Received a new incoming call.
+	CallStateReasonAccepted                                // Dialing or Ringing call is accepted.
+	CallStateReasonTerminated                              // Call is correctly terminated.
+	CallStateReasonRefusedOrBusy                           // Remote peer is busy or refused call.
+	CallStateReasonError                                   // Wrong number or generic network error.
+	CallStateReasonAudioSetupFailed                        // Error setting up audio channel.
+	CallStateReasonTransferred                             // Call has been transferred.
+	CallStateReasonDeflected                               // Call has been deflected to a new number.
+)
+
+func (r CallStateReason) String() string {
+	switch r {
+	case CallStateReasonOutgoingStarted:
+		return "Outgoing Started"
+	case CallStateReasonIncomingNew:
+		return "Incoming New"
+	case CallStateReasonAccepted:
+		return "Accepted"
+	case CallStateReasonTerminated:
+		return "Terminated"
+	case CallStateReasonRefusedOrBusy:
+		return "Refused Or Busy"
+	case CallStateReasonError:
+		return "Error"
+	case CallStateReasonAudioSetupFailed:
+		return "Audio Setup Failed"
+	case CallStateReasonTransferred:
+		return "Transferred"
+	case CallStateReasonDeflected:
+		return "Deflected"
+	default:
+		return "Unknown"
+	}
+}
+
+type CallDirection int32
+
+const (
+	CallDirectionUnknown  CallDirection = iota // Unknown.
+	CallDirectionIncoming                      // Call from network.
+	CallDirectionOutgoing                      // Call to network.
+)
+
+func (d CallDirection) String() string {
+	switch d {
+	case CallDirectionIncoming:
+		return "Incoming"
+	case CallDirectionOutgoing:
+		return "Outgoing"
+	default:
+		return "Unknown"
+	}
+}
+
+type ModemLock uint32
+
+const (
+	ModemLockUnknown     ModemLock = iota // Lock reason unknown.
+	ModemLockNone                         // Modem is unlocked.
+	ModemLockSimPin                       // SIM requires the PIN code.
+	ModemLockSimPin2                      // SIM requires the PIN2 code.
+	ModemLockSimPuk                       // SIM requires the PUK code.
+	ModemLockSimPuk2                      // SIM requires the PUK2 code.
+	ModemLockPhSpPin                      // Modem requires the service provider PIN code.
+	ModemLockPhSpPuk                      // Modem requires the service provider PUK code.
+	ModemLockPhNetPin                     // Modem requires the network PIN code.
+	ModemLockPhNetPuk                     // Modem requires the network PUK code.
+	ModemLockPhSimPin                     // Modem requires the PIN code.
+	ModemLockPhCorpPin                    // Modem requires the corporate PIN code.
+	ModemLockPhCorpPuk                    // Modem requires the corporate PUK code.
+	ModemLockPhFsimPin                    // Modem requires the PH-FSIM PIN code.
+	ModemLockPhFsimPuk                    // Modem requires the PH-FSIM PUK code.
+	ModemLockPhNetsubPin                  // Modem requires the network subset PIN code.
+	ModemLockPhNetsubPuk                  // Modem requires the network subset PUK code.
+)
+
+func (l ModemLock) String() string {
+	switch l {
+	case ModemLockNone:
+		return "none"
+	case ModemLockSimPin:
+		return "sim-pin"
+	case ModemLockSimPin2:
+		return "sim-pin2"
+	case ModemLockSimPuk:
+		return "sim-puk"
+	case ModemLockSimPuk2:
+		return "sim-puk2"
+	case ModemLockPhSpPin:
+		return "ph-sp-pin"
+	case ModemLockPhSpPuk:
+		return "ph-sp-puk"
+	case ModemLockPhNetPin:
+		return "ph-net-pin"
+	case ModemLockPhNetPuk:
+		return "ph-net-puk"
+	case ModemLockPhSimPin:
+		return "ph-sim-pin"
+	case ModemLockPhCorpPin:
+		return "ph-corp-pin"
+	case ModemLockPhCorpPuk:
+		return "ph-corp-puk"
+	case ModemLockPhFsimPin:
+		return "ph-fsim-pin"
+	case ModemLockPhFsimPuk:
+		return "ph-fsim-puk"
+	case ModemLockPhNetsubPin:
+		return "ph-netsub-pin"
+	case ModemLockPhNetsubPuk:
+		return "ph-netsub-puk"
+	default:
+		return "unknown"
+	}
+}
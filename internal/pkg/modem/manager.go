@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -19,8 +22,12 @@ const (
 
 	ModemManagerInterfacesAdded   = "org.freedesktop.DBus.ObjectManager.InterfacesAdded"
 	ModemManagerInterfacesRemoved = "org.freedesktop.DBus.ObjectManager.InterfacesRemoved"
+	ModemManagerPropertiesChanged = "org.freedesktop.DBus.Properties.PropertiesChanged"
+	ModemManagerNameOwnerChanged  = "org.freedesktop.DBus.NameOwnerChanged"
 )
 
+var ErrModemNotFound = errors.New("modem not found")
+
 type Manager struct {
 	dbusConn   *dbus.Conn
 	dbusObject dbus.BusObject
@@ -28,7 +35,12 @@ type Manager struct {
 	mu         sync.RWMutex
 	subs       []subscription
 	nextSubID  uint64
-	subscribe  sync.Once
+	// syncMu serialises the initial object enumeration with signal handling
+	// so that a signal arriving mid-enumeration is never overwritten.
+	syncMu sync.Mutex
+	synced atomic.Bool
+	// retrying holds the paths of rejected modems being created again.
+	retrying sync.Map
 }
 
 type ModemEventType int
@@ -36,6 +48,7 @@ type ModemEventType int
 const (
 	ModemEventAdded ModemEventType = iota
 	ModemEventRemoved
+	ModemEventUpdated
 )
 
 func (t ModemEventType) String() string {
@@ -44,6 +57,8 @@ func (t ModemEventType) String() string {
 		return "added"
 	case ModemEventRemoved:
 		return "removed"
+	case ModemEventUpdated:
+		return "updated"
 	default:
 		return "unknown"
 	}
@@ -54,6 +69,9 @@ type ModemEvent struct {
 	Modem    *Modem
 	Path     dbus.ObjectPath
 	Snapshot map[dbus.ObjectPath]*Modem
+	// Interface and Changed hold the D-Bus properties behind an updated event.
+	Interface string
+	Changed   map[string]dbus.Variant
 }
 
 type subscription struct {
@@ -71,6 +89,13 @@ func NewManager() (*Manager, error) {
 		return nil, err
 	}
 	m.dbusObject = m.dbusConn.Object(ModemManagerInterface, ModemManagerObjectPath)
+	if err := m.startSubscription(); err != nil {
+		return nil, err
+	}
+	if err := m.sync(); err != nil {
+		// ModemManager may not be running yet, the next lookup retries.
+		slog.Warn("failed to enumerate modems", "error", err)
+	}
 	return m, nil
 }
 
@@ -82,17 +107,52 @@ func (m *Manager) InhibitDevice(uid string, inhibit bool) error {
 	return m.dbusObject.Call(ModemManagerInterface+".InhibitDevice", 0, uid, inhibit).Err
 }
 
+// Modems returns a snapshot of the cached modems keyed by object path.
+// The cache is kept up to date by D-Bus signals, so no ModemManager
+// round trip is needed once the initial enumeration has succeeded.
 func (m *Manager) Modems() (map[dbus.ObjectPath]*Modem, error) {
+	if err := m.sync(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.copyModemsLocked(), nil
+}
+
+// Find returns the cached modem with the given EquipmentIdentifier.
+func (m *Manager) Find(id string) (*Modem, error) {
+	if err := m.sync(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, modem := range m.modems {
+		if modem.EquipmentIdentifier == id {
+			return modem, nil
+		}
+	}
+	return nil, ErrModemNotFound
+}
+
+func (m *Manager) sync() error {
+	if m.synced.Load() {
+		return nil
+	}
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+	if m.synced.Load() {
+		return nil
+	}
 	managedObjects := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant)
 	if err := m.dbusObject.Call(ModemManagerManagedObjects, 0).Store(&managedObjects); err != nil {
-		return nil, err
+		return err
 	}
 	modems := make(map[dbus.ObjectPath]*Modem, len(managedObjects))
 	for objectPath, data := range managedObjects {
-		if _, ok := data["org.freedesktop.ModemManager1.Modem"]; !ok {
+		if _, ok := data[ModemInterface]; !ok {
 			continue
 		}
-		modem, err := m.createModem(objectPath, data["org.freedesktop.ModemManager1.Modem"])
+		modem, err := m.createModem(objectPath, data[ModemInterface])
 		if err != nil {
 			slog.Error("failed to create modem", "error", err)
 			continue
@@ -101,27 +161,18 @@ func (m *Manager) Modems() (map[dbus.ObjectPath]*Modem, error) {
 	}
 	m.mu.Lock()
 	m.modems = modems
-	snapshot := m.copyModemsLocked()
 	m.mu.Unlock()
-	return snapshot, nil
+	m.synced.Store(true)
+	return nil
 }
 
 func (m *Manager) createModem(objectPath dbus.ObjectPath, data map[string]dbus.Variant) (*Modem, error) {
 	modem := Modem{
-		mmgr:                m,
-		objectPath:          objectPath,
-		dbusObject:          m.dbusConn.Object(ModemManagerInterface, objectPath),
-		Device:              data["Device"].Value().(string),
-		Manufacturer:        data["Manufacturer"].Value().(string),
-		EquipmentIdentifier: data["EquipmentIdentifier"].Value().(string),
-		Driver:              data["Drivers"].Value().([]string)[0],
-		Model:               data["Model"].Value().(string),
-		FirmwareRevision:    data["Revision"].Value().(string),
-		HardwareRevision:    data["HardwareRevision"].Value().(string),
-		State:               ModemState(data["State"].Value().(int32)),
-		PrimaryPort:         fmt.Sprintf("/dev/%s", data["PrimaryPort"].Value().(string)),
-		PrimarySimSlot:      data["PrimarySimSlot"].Value().(uint32),
+		mmgr:       m,
+		objectPath: objectPath,
+		dbusObject: m.dbusConn.Object(ModemManagerInterface, objectPath),
 	}
+	modem.applyProperties(data)
 	if modem.State == ModemStateDisabled {
 		slog.Info("enabling modem", "path", objectPath)
 		if err := modem.Enable(); err != nil {
@@ -129,28 +180,81 @@ func (m *Manager) createModem(objectPath dbus.ObjectPath, data map[string]dbus.V
 			return nil, err
 		}
 	}
+	variant, ok := data["Sim"]
+	if !ok {
+		return nil, errors.New("modem has no SIM property")
+	}
 	var err error
-	modem.Sim, err = modem.SIMs().Get(data["Sim"].Value().(dbus.ObjectPath))
+	simPath := variant.Value().(dbus.ObjectPath)
+	modem.Sim, err = modem.SIMs().Get(simPath)
 	if err != nil {
-		return nil, err
-	}
-	if numbers := data["OwnNumbers"].Value().([]string); len(numbers) > 0 {
-		modem.Number = numbers[0]
-	}
-	for _, port := range data["Ports"].Value().([][]any) {
-		modem.Ports = append(modem.Ports, ModemPort{
-			PortType: ModemPortType(port[1].(uint32)),
-			Device:   fmt.Sprintf("/dev/%s", port[0]),
-		})
-	}
-	for _, slot := range data["SimSlots"].Value().([]dbus.ObjectPath) {
-		if slot != "/" {
-			modem.SimSlots = append(modem.SimSlots, slot)
+		if modem.State != ModemStateLocked {
+			return nil, err
 		}
+		// A locked SIM may not expose its properties until it is unlocked,
+		// keep the modem so it can be unlocked remotely.
+		slog.Warn("failed to fetch locked SIM", "path", simPath, "error", err)
+		modem.Sim = &SIM{Path: simPath}
 	}
 	return &modem, nil
 }
 
+// applyProperties copies the known org.freedesktop.ModemManager1.Modem
+// properties into the modem. Properties missing from data are left untouched,
+// which allows the same code to handle both full and partial updates.
+func (m *Modem) applyProperties(data map[string]dbus.Variant) {
+	for name, variant := range data {
+		switch name {
+		case "Device":
+			m.Device = variant.Value().(string)
+		case "Manufacturer":
+			m.Manufacturer = variant.Value().(string)
+		case "EquipmentIdentifier":
+			m.EquipmentIdentifier = variant.Value().(string)
+		case "Drivers":
+			if drivers := variant.Value().([]string); len(drivers) > 0 {
+				m.Driver = drivers[0]
+			}
+		case "Model":
+			m.Model = variant.Value().(string)
+		case "Revision":
+			m.FirmwareRevision = variant.Value().(string)
+		case "HardwareRevision":
+			m.HardwareRevision = variant.Value().(string)
+		case "State":
+			m.State = ModemState(variant.Value().(int32))
+		case "PrimaryPort":
+			m.PrimaryPort = fmt.Sprintf("/dev/%s", variant.Value().(string))
+		case "PrimarySimSlot":
+			m.PrimarySimSlot = variant.Value().(uint32)
+		case "OwnNumbers":
+			m.Number = ""
+			if numbers := variant.Value().([]string); len(numbers) > 0 {
+				m.Number = numbers[0]
+			}
+		case "Ports":
+			m.Ports = nil
+			for _, port := range variant.Value().([][]any) {
+				m.Ports = append(m.Ports, ModemPort{
+					PortType: ModemPortType(port[1].(uint32)),
+					Device:   fmt.Sprintf("/dev/%s", port[0]),
+				})
+			}
+		case "UnlockRequired":
+			m.UnlockRequired = ModemLock(variant.Value().(uint32))
+		case "UnlockRetries":
+			m.UnlockRetries = unlockRetries(variant.Value().(map[uint32]uint32))
+		case "SimSlots":
+			m.SimSlots = nil
+			for _, slot := range variant.Value().([]dbus.ObjectPath) {
+				if slot != "/" {
+					m.SimSlots = append(m.SimSlots, slot)
+				}
+			}
+		}
+	}
+}
+
 func (m *Manager) Subscribe(subscriber func(ModemEvent) error) (func(), error) {
 	if subscriber == nil {
 		return nil, errors.New("subscriber is required")
@@ -161,22 +265,6 @@ func (m *Manager) Subscribe(subscriber func(ModemEvent) error) (func(), error) {
 	m.subs = append(m.subs, subscription{id: id, fn: subscriber})
 	m.mu.Unlock()
 
-	var err error
-	m.subscribe.Do(func() {
-		err = m.startSubscription()
-	})
-	if err != nil {
-		m.mu.Lock()
-		for i, sub := range m.subs {
-			if sub.id == id {
-				m.subs = append(m.subs[:i], m.subs[i+1:]...)
-				break
-			}
-		}
-		m.mu.Unlock()
-		return nil, err
-	}
-
 	unsubscribe := func() {
 		m.mu.Lock()
 		defer m.mu.Unlock()
@@ -237,14 +325,29 @@ func (m *Manager) startSubscription() error {
 	if err := m.dbusConn.AddMatchSignal(
 		dbus.WithMatchInterface("org.freedesktop.DBus.ObjectManager"),
 		dbus.WithMatchMember("InterfacesAdded"),
-		dbus.WithMatchPathNamespace("/org/freedesktop/ModemManager1"),
+		dbus.WithMatchPathNamespace(ModemManagerObjectPath),
 	); err != nil {
 		return err
 	}
 	if err := m.dbusConn.AddMatchSignal(
 		dbus.WithMatchInterface("org.freedesktop.DBus.ObjectManager"),
 		dbus.WithMatchMember("InterfacesRemoved"),
-		dbus.WithMatchPathNamespace("/org/freedesktop/ModemManager1"),
+		dbus.WithMatchPathNamespace(ModemManagerObjectPath),
+	); err != nil {
+		return err
+	}
+	if err := m.dbusConn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchPathNamespace(ModemManagerObjectPath),
+	); err != nil {
+		return err
+	}
+	if err := m.dbusConn.AddMatchSignal(
+		dbus.WithMatchSender("org.freedesktop.DBus"),
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchArg(0, ModemManagerInterface),
 	); err != nil {
 		return err
 	}
@@ -257,46 +360,202 @@ func (m *Manager) startSubscription() error {
 
 func (m *Manager) handleSignals(sig <-chan *dbus.Signal) {
 	for event := range sig {
-		modemPath := event.Body[0].(dbus.ObjectPath)
-		var (
-			modem     *Modem
-			eventType ModemEventType
-		)
-		if event.Name == ModemManagerInterfacesAdded {
-			eventType = ModemEventAdded
-			slog.Info("new modem plugged in", "path", modemPath)
-			raw := event.Body[1].(map[string]map[string]dbus.Variant)
-			var err error
-			modem, err = m.createModem(modemPath, raw["org.freedesktop.ModemManager1.Modem"])
-			if err != nil {
-				slog.Error("failed to create modem", "error", err)
-				continue
-			}
-		} else {
-			eventType = ModemEventRemoved
-			slog.Info("modem unplugged", "path", modemPath)
+		var events []ModemEvent
+		m.syncMu.Lock()
+		switch event.Name {
+		case ModemManagerInterfacesAdded:
+			events = m.handleInterfacesAdded(event)
+		case ModemManagerInterfacesRemoved:
+			events = m.handleInterfacesRemoved(event)
+		case ModemManagerPropertiesChanged:
+			events = m.handlePropertiesChanged(event)
+		case ModemManagerNameOwnerChanged:
+			events = m.handleNameOwnerChanged(event)
 		}
+		m.syncMu.Unlock()
+		// Subscribers may call back into the manager, which takes syncMu
+		// until the first enumeration succeeded, so publish without it.
+		for _, e := range events {
+			m.publish(e)
+		}
+	}
+}
 
-		m.mu.Lock()
-		if eventType == ModemEventAdded {
-			m.deleteAndUpdate(modem)
-		} else {
-			modem = m.modems[modemPath]
-			delete(m.modems, modemPath)
+func (m *Manager) handleInterfacesAdded(event *dbus.Signal) []ModemEvent {
+	modemPath := event.Body[0].(dbus.ObjectPath)
+	raw := event.Body[1].(map[string]map[string]dbus.Variant)
+	data, ok := raw[ModemInterface]
+	if !ok {
+		return nil
+	}
+	slog.Info("new modem plugged in", "path", modemPath)
+	return m.addModem(modemPath, data)
+}
+
+// addModem creates the modem and caches it. A modem that cannot be created,
+// e.g. because it has no SIM yet, is created again once its SIM or state
+// changes, see retryModem.
+func (m *Manager) addModem(modemPath dbus.ObjectPath, data map[string]dbus.Variant) []ModemEvent {
+	modem, err := m.createModem(modemPath, data)
+	if err != nil {
+		slog.Error("failed to create modem", "path", modemPath, "error", err)
+		return nil
+	}
+	m.mu.Lock()
+	m.deleteAndUpdate(modem)
+	m.mu.Unlock()
+	return []ModemEvent{{Type: ModemEventAdded, Path: modemPath, Modem: modem}}
+}
+
+func (m *Manager) handleInterfacesRemoved(event *dbus.Signal) []ModemEvent {
+	modemPath := event.Body[0].(dbus.ObjectPath)
+	if !slices.Contains(event.Body[1].([]string), ModemInterface) {
+		return nil
+	}
+	slog.Info("modem unplugged", "path", modemPath)
+	m.mu.Lock()
+	modem := m.modems[modemPath]
+	delete(m.modems, modemPath)
+	m.mu.Unlock()
+	return []ModemEvent{{Type: ModemEventRemoved, Path: modemPath, Modem: modem}}
+}
+
+func (m *Manager) handlePropertiesChanged(event *dbus.Signal) []ModemEvent {
+	iface := event.Body[0].(string)
+	changed := event.Body[1].(map[string]dbus.Variant)
+	switch {
+	case iface == ModemSimInterface:
+		return m.refreshSim(event.Path)
+	case strings.HasPrefix(iface, ModemInterface):
+		return m.updateModem(event.Path, iface, changed)
+	}
+	return nil
+}
+
+// updateModem replaces the cached modem with an updated copy, so callers
+// holding the previous pointer never observe a partially applied update.
+func (m *Manager) updateModem(path dbus.ObjectPath, iface string, changed map[string]dbus.Variant) []ModemEvent {
+	m.mu.RLock()
+	current, ok := m.modems[path]
+	m.mu.RUnlock()
+	if !ok {
+		if iface != ModemInterface || !m.synced.Load() {
+			return nil
 		}
-		snapshot := m.copyModemsLocked()
-		subscribers := append([]subscription(nil), m.subs...)
+		// The modem was rejected when it appeared, try again once a change
+		// may have made it usable, e.g. because a SIM was inserted.
+		_, sim := changed["Sim"]
+		_, state := changed["State"]
+		if sim || state {
+			go m.retryModem(path)
+		}
+		return nil
+	}
+	updated := *current
+	if iface == ModemInterface {
+		updated.applyProperties(changed)
+		if current.State == ModemStateLocked && updated.State == ModemStateDisabled {
+			// Modems are enabled when they are found, do the same once a
+			// locked one has been unlocked.
+			slog.Info("enabling unlocked modem", "path", path)
+			go func() {
+				if err := updated.Enable(); err != nil {
+					slog.Error("failed to enable modem", "path", path, "error", err)
+				}
+			}()
+		}
+		if variant, ok := changed["Sim"]; ok {
+			if simPath := variant.Value().(dbus.ObjectPath); simPath != "/" {
+				sim, err := updated.SIMs().Get(simPath)
+				if err != nil {
+					slog.Error("failed to refresh SIM", "path", simPath, "error", err)
+				} else {
+					updated.Sim = sim
+				}
+			}
+		}
+	}
+	m.mu.Lock()
+	if m.modems[path] != current {
+		// A newer event already replaced the modem.
 		m.mu.Unlock()
+		return nil
+	}
+	m.modems[path] = &updated
+	m.mu.Unlock()
+	return []ModemEvent{{Type: ModemEventUpdated, Path: path, Modem: &updated, Interface: iface, Changed: changed}}
+}
 
-		for _, subscriber := range subscribers {
-			if err := subscriber.fn(ModemEvent{
-				Type:     eventType,
-				Modem:    modem,
-				Path:     modemPath,
-				Snapshot: snapshot,
-			}); err != nil {
-				slog.Error("failed to process modem", "error", err)
-			}
+// retryModem creates a modem that was rejected when it appeared. It runs
+// outside the signal loop, as creating a modem may enable it.
+func (m *Manager) retryModem(path dbus.ObjectPath) {
+	if _, loaded := m.retrying.LoadOrStore(path, struct{}{}); loaded {
+		return
+	}
+	defer m.retrying.Delete(path)
+	data := make(map[string]dbus.Variant)
+	if err := m.dbusConn.Object(ModemManagerInterface, path).Call("org.freedesktop.DBus.Properties.GetAll", 0, ModemInterface).Store(&data); err != nil {
+		slog.Error("failed to fetch modem properties", "path", path, "error", err)
+		return
+	}
+	modem, err := m.createModem(path, data)
+	if err != nil {
+		slog.Warn("modem is still not usable", "path", path, "error", err)
+		return
+	}
+	m.mu.Lock()
+	if _, ok := m.modems[path]; ok {
+		// The modem was added by a signal in the meantime.
+		m.mu.Unlock()
+		return
+	}
+	m.deleteAndUpdate(modem)
+	m.mu.Unlock()
+	m.publish(ModemEvent{Type: ModemEventAdded, Path: path, Modem: modem})
+}
+
+func (m *Manager) refreshSim(simPath dbus.ObjectPath) []ModemEvent {
+	m.mu.RLock()
+	var owner dbus.ObjectPath
+	for path, modem := range m.modems {
+		if modem.Sim != nil && modem.Sim.Path == simPath {
+			owner = path
+			break
+		}
+	}
+	m.mu.RUnlock()
+	if owner == "" {
+		return nil
+	}
+	return m.updateModem(owner, ModemInterface, map[string]dbus.Variant{"Sim": dbus.MakeVariant(simPath)})
+}
+
+func (m *Manager) handleNameOwnerChanged(event *dbus.Signal) []ModemEvent {
+	if newOwner := event.Body[2].(string); newOwner != "" {
+		return nil
+	}
+	// ModemManager left the bus, none of the cached objects exist anymore.
+	slog.Warn("modem manager disappeared from the bus")
+	m.mu.Lock()
+	removed := m.modems
+	m.modems = make(map[dbus.ObjectPath]*Modem, len(removed))
+	m.mu.Unlock()
+	events := make([]ModemEvent, 0, len(removed))
+	for path, modem := range removed {
+		events = append(events, ModemEvent{Type: ModemEventRemoved, Path: path, Modem: modem})
+	}
+	return events
+}
+
+func (m *Manager) publish(event ModemEvent) {
+	m.mu.RLock()
+	event.Snapshot = m.copyModemsLocked()
+	subscribers := append([]subscription(nil), m.subs...)
+	m.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		if err := subscriber.fn(event); err != nil {
+			slog.Error("failed to process modem", "error", err)
 		}
 	}
 }
@@ -10,6 +10,10 @@ import (
 
 const ModemMessagingInterface = ModemInterface + ".Messaging"
 
+// outgoingSMSTimeout bounds how long Subscribe waits for an outgoing message
+// to be sent before giving up on it, e.g. for drafts that are never sent.
+const outgoingSMSTimeout = 5 * time.Minute
+
 type Messaging struct {
 	modem *Modem
 }
@@ -68,10 +72,12 @@ func (msg *Messaging) Subscribe(ctx context.Context, subscriber func(message *SM
 	for {
 		select {
 		case sig := <-signalChan:
+			path := sig.Body[0].(dbus.ObjectPath)
 			if !sig.Body[1].(bool) {
+				go msg.deliverSent(ctx, path, subscriber)
 				continue
 			}
-			s, err := msg.waitForSMSReceived(ctx, sig.Body[0].(dbus.ObjectPath), 100*time.Millisecond)
+			s, err := msg.waitForSMSState(ctx, path, SMSStateReceived, 100*time.Millisecond)
 			if err != nil {
 				slog.Error("failed to process message", "error", err, "path", sig.Path)
 				continue
@@ -86,7 +92,22 @@ func (msg *Messaging) Subscribe(ctx context.Context, subscriber func(message *SM
 	}
 }
 
-func (msg *Messaging) waitForSMSReceived(ctx context.Context, path dbus.ObjectPath, interval time.Duration) (*SMS, error) {
+// deliverSent passes an outgoing message to the subscriber once it has been
+// sent. Messages that are not sent within outgoingSMSTimeout are ignored.
+func (msg *Messaging) deliverSent(ctx context.Context, path dbus.ObjectPath, subscriber func(message *SMS) error) {
+	ctx, cancel := context.WithTimeout(ctx, outgoingSMSTimeout)
+	defer cancel()
+	s, err := msg.waitForSMSState(ctx, path, SMSStateSent, time.Second)
+	if err != nil {
+		slog.Debug("outgoing message not sent", "error", err, "path", path)
+		return
+	}
+	if err := subscriber(s); err != nil {
+		slog.Error("failed to process message", "error", err, "path", path)
+	}
+}
+
+func (msg *Messaging) waitForSMSState(ctx context.Context, path dbus.ObjectPath, state SMSState, interval time.Duration) (*SMS, error) {
 	if interval <= 0 {
 		interval = 100 * time.Millisecond
 	}
@@ -97,7 +118,7 @@ func (msg *Messaging) waitForSMSReceived(ctx context.Context, path dbus.ObjectPa
 		if err != nil {
 			return nil, err
 		}
-		if s.State == SMSStateReceived {
+		if s.State == state {
 			return s, nil
 		}
 		select {
@@ -30,6 +30,8 @@ type Modem struct {
 	PrimarySimSlot      uint32
 	Sim                 *SIM
 	State               ModemState
+	UnlockRequired      ModemLock
+	UnlockRetries       map[ModemLock]uint32
 }
 
 type ModemPort struct {
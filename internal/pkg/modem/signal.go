@@ -0,0 +1,90 @@
+package modem
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+const ModemSignalInterface = ModemInterface + ".Signal"
+
+type Signal struct {
+	modem *Modem
+}
+
+func (m *Modem) Signal() *Signal {
+	return &Signal{modem: m}
+}
+
+// SignalMeasurement holds the values ModemManager reports for one access
+// technology. Values the modem does not report are nil. ModemManager reports
+// the SINR of LTE and 5G NR as SNR.
+type SignalMeasurement struct {
+	RSSI      *float64 // dBm
+	RSCP      *float64 // dBm, UMTS only
+	ECIO      *float64 // dB, UMTS only
+	RSRP      *float64 // dBm
+	RSRQ      *float64 // dB
+	SNR       *float64 // dB
+	ErrorRate *float64 // percent
+}
+
+// SignalInfo is a snapshot of the extended signal information. Technologies
+// the modem is not using are nil.
+type SignalInfo struct {
+	Rate uint32
+	Gsm  *SignalMeasurement
+	Umts *SignalMeasurement
+	Lte  *SignalMeasurement
+	Nr5g *SignalMeasurement
+}
+
+// Setup sets how often, in seconds, the modem refreshes the extended signal
+// information. A rate of 0 disables the refresh.
+func (s *Signal) Setup(rate uint32) error {
+	return s.modem.dbusObject.Call(ModemSignalInterface+".Setup", 0, rate).Err
+}
+
+func (s *Signal) Rate() (uint32, error) {
+	variant, err := s.modem.dbusObject.GetProperty(ModemSignalInterface + ".Rate")
+	if err != nil {
+		return 0, err
+	}
+	return variant.Value().(uint32), nil
+}
+
+func (s *Signal) Get() (*SignalInfo, error) {
+	var properties map[string]dbus.Variant
+	if err := s.modem.dbusObject.Call("org.freedesktop.DBus.Properties.GetAll", 0, ModemSignalInterface).Store(&properties); err != nil {
+		return nil, err
+	}
+	var info SignalInfo
+	if rate, ok := properties["Rate"].Value().(uint32); ok {
+		info.Rate = rate
+	}
+	info.Gsm = signalMeasurement(properties["Gsm"])
+	info.Umts = signalMeasurement(properties["Umts"])
+	info.Lte = signalMeasurement(properties["Lte"])
+	info.Nr5g = signalMeasurement(properties["Nr5g"])
+	return &info, nil
+}
+
+func signalMeasurement(variant dbus.Variant) *SignalMeasurement {
+	values, ok := variant.Value().(map[string]dbus.Variant)
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	value := func(key string) *float64 {
+		if v, ok := values[key].Value().(float64); ok {
+			return &v
+		}
+		return nil
+	}
+	return &SignalMeasurement{
+		RSSI:      value("rssi"),
+		RSCP:      value("rscp"),
+		ECIO:      value("ecio"),
+		RSRP:      value("rsrp"),
+		RSRQ:      value("rsrq"),
+		SNR:       value("snr"),
+		ErrorRate: value("error-rate"),
+	}
+}
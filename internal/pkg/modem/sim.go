@@ -2,11 +2,20 @@ package modem
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/godbus/dbus/v5"
 )
 
-const ModemSimInterface = ModemManagerInterface + ".Sim"
+const (
+	ModemSimInterface    = ModemManagerInterface + ".Sim"
+	mobileEquipmentError = ModemManagerInterface + ".Error.MobileEquipment"
+)
+
+var (
+	ErrIncorrectPassword = errors.New("incorrect PIN or PUK")
+	ErrSimPukRequired    = errors.New("SIM PUK required")
+)
 
 type SIMs struct {
 	modem *Modem
@@ -79,3 +88,68 @@ func (sims *SIMs) Get(path dbus.ObjectPath) (*SIM, error) {
 	sim.OperatorName = variant.Value().(string)
 	return sim, nil
 }
+
+// SendPin unlocks the SIM with its PIN.
+func (s *SIM) SendPin(pin string) error {
+	return s.call("SendPin", pin)
+}
+
+// SendPuk unlocks a SIM blocked by too many wrong PINs and sets its PIN.
+func (s *SIM) SendPuk(puk string, pin string) error {
+	return s.call("SendPuk", puk, pin)
+}
+
+// EnablePin turns the PIN check on or off.
+func (s *SIM) EnablePin(pin string, enabled bool) error {
+	return s.call("EnablePin", pin, enabled)
+}
+
+func (s *SIM) ChangePin(oldPin string, newPin string) error {
+	return s.call("ChangePin", oldPin, newPin)
+}
+
+func (s *SIM) call(method string, args ...any) error {
+	dbusObject, err := systemBusObject(s.Path)
+	if err != nil {
+		return err
+	}
+	return simError(dbusObject.Call(ModemSimInterface+"."+method, 0, args...).Err)
+}
+
+// simError maps the ModemManager errors callers act upon.
+func simError(err error) error {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return err
+	}
+	switch dbusErr.Name {
+	case mobileEquipmentError + ".IncorrectPassword":
+		return fmt.Errorf("%w: %w", ErrIncorrectPassword, err)
+	case mobileEquipmentError + ".SimPuk":
+		return fmt.Errorf("%w: %w", ErrSimPukRequired, err)
+	}
+	return err
+}
+
+// UnlockStatus reads the lock the modem is waiting for and the remaining
+// attempts of each lock from the modem, bypassing the cached properties.
+func (m *Modem) UnlockStatus() (ModemLock, map[ModemLock]uint32, error) {
+	variant, err := m.dbusObject.GetProperty(ModemInterface + ".UnlockRequired")
+	if err != nil {
+		return ModemLockUnknown, nil, err
+	}
+	lock := ModemLock(variant.Value().(uint32))
+	variant, err = m.dbusObject.GetProperty(ModemInterface + ".UnlockRetries")
+	if err != nil {
+		return ModemLockUnknown, nil, err
+	}
+	return lock, unlockRetries(variant.Value().(map[uint32]uint32)), nil
+}
+
+func unlockRetries(values map[uint32]uint32) map[ModemLock]uint32 {
+	retries := make(map[ModemLock]uint32, len(values))
+	for lock, count := range values {
+		retries[ModemLock(lock)] = count
+	}
+	return retries
+}
@@ -1,6 +1,8 @@
 package modem
 
 import (
+	"context"
+	"log/slog"
 	"time"
 
 	"github.com/godbus/dbus/v5"
@@ -9,11 +11,15 @@ import (
 const ModemSMSInterface = ModemManagerInterface + ".Sms"
 
 type SMS struct {
-	objectPath dbus.ObjectPath
-	State      SMSState
-	Number     string
-	Text       string
-	Timestamp  time.Time
+	objectPath            dbus.ObjectPath
+	State                 SMSState
+	Number                string
+	Text                  string
+	Timestamp             time.Time
+	DeliveryReportRequest bool
+	MessageReference      uint32
+	DeliveryState         SMSDeliveryState
+	DischargeTimestamp    time.Time
 }
 
 func (sms *SMS) Path() dbus.ObjectPath {
@@ -25,44 +31,53 @@ func (msg *Messaging) Retrieve(objectPath dbus.ObjectPath) (*SMS, error) {
 	if err != nil {
 		return nil, err
 	}
-	sms := SMS{objectPath: objectPath}
-	variant, err := dbusObject.GetProperty(ModemSMSInterface + ".State")
-	if err != nil {
+	var properties map[string]dbus.Variant
+	if err := dbusObject.Call("org.freedesktop.DBus.Properties.GetAll", 0, ModemSMSInterface).Store(&properties); err != nil {
 		return nil, err
 	}
-	sms.State = SMSState(variant.Value().(uint32))
-
-	variant, err = dbusObject.GetProperty(ModemSMSInterface + ".Number")
-	if err != nil {
+	sms := SMS{objectPath: objectPath, DeliveryState: SMSDeliveryStateUnknown}
+	if state, ok := properties["State"].Value().(uint32); ok {
+		sms.State = SMSState(state)
+	}
+	sms.Number, _ = properties["Number"].Value().(string)
+	sms.Text, _ = properties["Text"].Value().(string)
+	sms.DeliveryReportRequest, _ = properties["DeliveryReportRequest"].Value().(bool)
+	sms.MessageReference, _ = properties["MessageReference"].Value().(uint32)
+	if state, ok := properties["DeliveryState"].Value().(uint32); ok {
+		sms.DeliveryState = SMSDeliveryState(state)
+	}
+	if sms.Timestamp, err = parseSMSTimestamp(properties["Timestamp"]); err != nil {
 		return nil, err
 	}
-	sms.Number = variant.Value().(string)
-
-	variant, err = dbusObject.GetProperty(ModemSMSInterface + ".Text")
-	if err != nil {
+	if sms.DischargeTimestamp, err = parseSMSTimestamp(properties["DischargeTimestamp"]); err != nil {
 		return nil, err
 	}
-	sms.Text = variant.Value().(string)
+	return &sms, nil
+}
 
-	variant, err = dbusObject.GetProperty(ModemSMSInterface + ".Timestamp")
-	if err != nil {
-		return nil, err
+// parseSMSTimestamp parses the ISO-8601 timestamps of ModemManager, whose
+// offsets may lack the minutes, e.g. 2024-01-02T03:04:05+08.
+func parseSMSTimestamp(variant dbus.Variant) (time.Time, error) {
+	t, _ := variant.Value().(string)
+	if t == "" {
+		return time.Time{}, nil
 	}
-	if t := variant.Value().(string); t != "" {
-		if len(t) >= 3 && (t[len(t)-3] == '+' || t[len(t)-3] == '-') {
-			t = t + ":00"
-		}
-		sms.Timestamp, err = time.Parse(time.RFC3339, t)
-		if err != nil {
-			return nil, err
-		}
+	if len(t) >= 3 && (t[len(t)-3] == '+' || t[len(t)-3] == '-') {
+		t = t + ":00"
 	}
-	return &sms, nil
+	return time.Parse(time.RFC3339, t)
 }
 
+// Send sends the text and asks the SMSC for a delivery report, see
+// WatchDeliveries.
 func (msg *Messaging) Send(to string, text string) (*SMS, error) {
-	path, err := msg.Create(to, text)
-	if err != nil {
+	var path dbus.ObjectPath
+	data := map[string]any{
+		"number":                  to,
+		"text":                    text,
+		"delivery-report-request": true,
+	}
+	if err := msg.modem.dbusObject.Call(ModemMessagingInterface+".Create", 0, &data).Store(&path); err != nil {
 		return nil, err
 	}
 	dbusObject, err := systemBusObject(path)
@@ -74,3 +89,105 @@ func (msg *Messaging) Send(to string, text string) (*SMS, error) {
 	}
 	return msg.Retrieve(path)
 }
+
+// deliveryReportTimeout bounds how long a sent message is tracked waiting for
+// its delivery report.
+const deliveryReportTimeout = 24 * time.Hour
+
+type delivery struct {
+	last  *SMS
+	since time.Time
+}
+
+// WatchDeliveries calls subscriber with an outgoing message of the modem
+// every time its state or delivery state changes, until the SMSC reports it
+// delivered or failed, the message is deleted or deliveryReportTimeout has
+// passed. Messages stored on the modem that still wait for their report are
+// watched as well. A single connection serves every message of the modem.
+func (msg *Messaging) WatchDeliveries(ctx context.Context, subscriber func(message *SMS)) error {
+	dbusConn, err := systemBusPrivate()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dbusConn.Close(); err != nil {
+			slog.Error("failed to close dbus connection", "error", err)
+		}
+	}()
+	if err := dbusConn.AddMatchSignal(
+		dbus.WithMatchInterface(ModemMessagingInterface),
+		dbus.WithMatchMember("Added"),
+		dbus.WithMatchObjectPath(msg.modem.objectPath),
+	); err != nil {
+		return err
+	}
+	if err := dbusConn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchPathNamespace(ModemManagerObjectPath+"/SMS"),
+		dbus.WithMatchArg(0, ModemSMSInterface),
+	); err != nil {
+		return err
+	}
+	signalChan := make(chan *dbus.Signal, 10)
+	dbusConn.Signal(signalChan)
+	defer dbusConn.RemoveSignal(signalChan)
+
+	stored, err := msg.List()
+	if err != nil {
+		return err
+	}
+	deliveries := make(map[dbus.ObjectPath]*delivery)
+	for _, s := range stored {
+		if s.State == SMSStateSent && s.DeliveryReportRequest && !s.DeliveryState.Delivered() && !s.DeliveryState.Failed() {
+			deliveries[s.Path()] = &delivery{last: s, since: time.Now()}
+		}
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case sig := <-signalChan:
+			switch sig.Name {
+			case ModemMessagingInterface + ".Added":
+				if received := sig.Body[1].(bool); received {
+					continue
+				}
+				path := sig.Body[0].(dbus.ObjectPath)
+				deliveries[path] = &delivery{since: time.Now()}
+				msg.refreshDelivery(deliveries, path, subscriber)
+			case ModemManagerPropertiesChanged:
+				if _, ok := deliveries[sig.Path]; ok {
+					msg.refreshDelivery(deliveries, sig.Path, subscriber)
+				}
+			}
+		case <-ticker.C:
+			for path, d := range deliveries {
+				if time.Since(d.since) > deliveryReportTimeout {
+					delete(deliveries, path)
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (msg *Messaging) refreshDelivery(deliveries map[dbus.ObjectPath]*delivery, path dbus.ObjectPath, subscriber func(message *SMS)) {
+	d := deliveries[path]
+	s, err := msg.Retrieve(path)
+	if err != nil {
+		// ModemManager drops the object when the message is deleted.
+		slog.Debug("stopped tracking SMS delivery", "path", path, "error", err)
+		delete(deliveries, path)
+		return
+	}
+	if d.last == nil || s.State != d.last.State || s.DeliveryState != d.last.DeliveryState {
+		subscriber(s)
+	}
+	d.last = s
+	if s.DeliveryState.Delivered() || s.DeliveryState.Failed() || (s.State == SMSStateSent && !s.DeliveryReportRequest) {
+		delete(deliveries, path)
+	}
+}
@@ -0,0 +1,245 @@
+package modem
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path"
+	"slices"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	ModemVoiceInterface = ModemInterface + ".Voice"
+	ModemCallInterface  = ModemManagerInterface + ".Call"
+)
+
+var ErrCallNotFound = errors.New("call not found")
+
+type Voice struct {
+	modem *Modem
+}
+
+func (m *Modem) Voice() *Voice {
+	return &Voice{modem: m}
+}
+
+type Call struct {
+	objectPath  dbus.ObjectPath
+	dbusObject  dbus.BusObject
+	State       CallState
+	StateReason CallStateReason
+	Direction   CallDirection
+	Number      string
+}
+
+func (c *Call) Path() dbus.ObjectPath {
+	return c.objectPath
+}
+
+// ID returns the index ModemManager gives the call, e.g. "2" for
+// /org/freedesktop/ModemManager1/Call/2.
+func (c *Call) ID() string {
+	return path.Base(string(c.objectPath))
+}
+
+// Start dials an outgoing call created with CreateCall.
+func (c *Call) Start() error {
+	return c.dbusObject.Call(ModemCallInterface+".Start", 0).Err
+}
+
+func (c *Call) Accept() error {
+	return c.dbusObject.Call(ModemCallInterface+".Accept", 0).Err
+}
+
+func (c *Call) Hangup() error {
+	return c.dbusObject.Call(ModemCallInterface+".Hangup", 0).Err
+}
+
+func (c *Call) SendDtmf(tones string) error {
+	return c.dbusObject.Call(ModemCallInterface+".SendDtmf", 0, tones).Err
+}
+
+func (v *Voice) ListCalls() ([]*Call, error) {
+	var paths []dbus.ObjectPath
+	if err := v.modem.dbusObject.Call(ModemVoiceInterface+".ListCalls", 0).Store(&paths); err != nil {
+		return nil, err
+	}
+	calls := make([]*Call, 0, len(paths))
+	for _, p := range paths {
+		call, err := v.RetrieveCall(p)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// FindCall returns the call of this modem with the given ID.
+func (v *Voice) FindCall(id string) (*Call, error) {
+	var paths []dbus.ObjectPath
+	if err := v.modem.dbusObject.Call(ModemVoiceInterface+".ListCalls", 0).Store(&paths); err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(paths, func(p dbus.ObjectPath) bool {
+		return path.Base(string(p)) == id
+	})
+	if index < 0 {
+		return nil, ErrCallNotFound
+	}
+	return v.RetrieveCall(paths[index])
+}
+
+func (v *Voice) RetrieveCall(objectPath dbus.ObjectPath) (*Call, error) {
+	dbusObject, err := systemBusObject(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	var properties map[string]dbus.Variant
+	if err := dbusObject.Call("org.freedesktop.DBus.Properties.GetAll", 0, ModemCallInterface).Store(&properties); err != nil {
+		return nil, err
+	}
+	call := &Call{objectPath: objectPath, dbusObject: dbusObject}
+	if state, ok := properties["State"].Value().(int32); ok {
+		call.State = CallState(state)
+	}
+	if reason, ok := properties["StateReason"].Value().(int32); ok {
+		call.StateReason = CallStateReason(reason)
+	}
+	if direction, ok := properties["Direction"].Value().(int32); ok {
+		call.Direction = CallDirection(direction)
+	}
+	call.Number, _ = properties["Number"].Value().(string)
+	return call, nil
+}
+
+// CreateCall creates an outgoing call to the number. Start dials it.
+func (v *Voice) CreateCall(number string) (*Call, error) {
+	var objectPath dbus.ObjectPath
+	properties := map[string]dbus.Variant{"number": dbus.MakeVariant(number)}
+	if err := v.modem.dbusObject.Call(ModemVoiceInterface+".CreateCall", 0, properties).Store(&objectPath); err != nil {
+		return nil, err
+	}
+	return v.RetrieveCall(objectPath)
+}
+
+func (v *Voice) DeleteCall(objectPath dbus.ObjectPath) error {
+	return v.modem.dbusObject.Call(ModemVoiceInterface+".DeleteCall", 0, objectPath).Err
+}
+
+func (v *Voice) HangupAll() error {
+	return v.modem.dbusObject.Call(ModemVoiceInterface+".HangupAll", 0).Err
+}
+
+// Subscribe calls subscriber for every call added to the modem, incoming or
+// outgoing, until the context is canceled.
+func (v *Voice) Subscribe(ctx context.Context, subscriber func(call *Call) error) error {
+	dbusConn, err := systemBusPrivate()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dbusConn.Close(); err != nil {
+			slog.Error("failed to close dbus connection", "error", err)
+		}
+	}()
+	if err := dbusConn.AddMatchSignal(
+		dbus.WithMatchInterface(ModemVoiceInterface),
+		dbus.WithMatchMember("CallAdded"),
+		dbus.WithMatchObjectPath(v.modem.objectPath),
+	); err != nil {
+		return err
+	}
+	signalChan := make(chan *dbus.Signal, 10)
+	dbusConn.Signal(signalChan)
+	defer dbusConn.RemoveSignal(signalChan)
+	for {
+		select {
+		case sig := <-signalChan:
+			path, ok := sig.Body[0].(dbus.ObjectPath)
+			if !ok {
+				continue
+			}
+			call, err := v.RetrieveCall(path)
+			if err != nil {
+				slog.Error("failed to process call", "error", err, "path", path)
+				continue
+			}
+			if err := subscriber(call); err != nil {
+				slog.Error("failed to process call", "error", err, "path", path)
+			}
+		case <-ctx.Done():
+			slog.Info("unsubscribing from modem voice", "path", v.modem.dbusObject.Path())
+			return nil
+		}
+	}
+}
+
+// WatchCall calls subscriber with every state the call enters, starting with
+// the current one, until the call is terminated, deleted or the context is
+// canceled. A deleted call is reported as terminated.
+func (v *Voice) WatchCall(ctx context.Context, call *Call, subscriber func(state CallState, reason CallStateReason)) error {
+	dbusConn, err := systemBusPrivate()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := dbusConn.Close(); err != nil {
+			slog.Error("failed to close dbus connection", "error", err)
+		}
+	}()
+	if err := dbusConn.AddMatchSignal(
+		dbus.WithMatchInterface(ModemCallInterface),
+		dbus.WithMatchMember("StateChanged"),
+		dbus.WithMatchObjectPath(call.objectPath),
+	); err != nil {
+		return err
+	}
+	if err := dbusConn.AddMatchSignal(
+		dbus.WithMatchInterface(ModemVoiceInterface),
+		dbus.WithMatchMember("CallDeleted"),
+		dbus.WithMatchObjectPath(v.modem.objectPath),
+	); err != nil {
+		return err
+	}
+	signalChan := make(chan *dbus.Signal, 10)
+	dbusConn.Signal(signalChan)
+	defer dbusConn.RemoveSignal(signalChan)
+
+	// The call may have changed state before the match was in place.
+	current, err := v.RetrieveCall(call.objectPath)
+	if err != nil {
+		subscriber(CallStateTerminated, call.StateReason)
+		return nil
+	}
+	subscriber(current.State, current.StateReason)
+	if current.State == CallStateTerminated {
+		return nil
+	}
+	for {
+		select {
+		case sig := <-signalChan:
+			switch sig.Name {
+			case ModemCallInterface + ".StateChanged":
+				if len(sig.Body) < 3 {
+					continue
+				}
+				state, _ := sig.Body[1].(int32)
+				reason, _ := sig.Body[2].(uint32)
+				subscriber(CallState(state), CallStateReason(reason))
+				if CallState(state) == CallStateTerminated {
+					return nil
+				}
+			case ModemVoiceInterface + ".CallDeleted":
+				if p, ok := sig.Body[0].(dbus.ObjectPath); ok && p == call.objectPath {
+					subscriber(CallStateTerminated, CallStateReasonUnknown)
+					return nil
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
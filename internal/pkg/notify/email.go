@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/damonto/sigmo/internal/pkg/config"
+)
+
+const (
+	emailSecurityStartTLS = "starttls"
+	emailSecurityTLS      = "tls"
+	emailSecurityNone     = "none"
+
+	emailTimeout = 10 * time.Second
+)
+
+type Email struct {
+	address    string
+	host       string
+	security   string
+	username   string
+	password   string
+	from       *mail.Address
+	recipients []*mail.Address
+}
+
+func NewEmail(cfg *config.Channel) (*Email, error) {
+	if cfg == nil {
+		return nil, errors.New("email config is required")
+	}
+	address := strings.TrimSpace(cfg.Endpoint)
+	if address == "" {
+		return nil, errors.New("email endpoint is required")
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("email endpoint must be host:port: %w", err)
+	}
+	security := strings.ToLower(strings.TrimSpace(cfg.Security))
+	switch security {
+	case "":
+		security = emailSecurityStartTLS
+	case emailSecurityStartTLS, emailSecurityTLS, emailSecurityNone:
+	default:
+		return nil, fmt.Errorf("email security must be %s, %s or %s", emailSecurityStartTLS, emailSecurityTLS, emailSecurityNone)
+	}
+	from, err := mail.ParseAddress(strings.TrimSpace(cfg.From))
+	if err != nil {
+		return nil, fmt.Errorf("parsing email sender: %w", err)
+	}
+	var recipients []*mail.Address
+	for _, recipient := range cfg.Recipients.Strings() {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("parsing email recipient %q: %w", recipient, err)
+		}
+		recipients = append(recipients, address)
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("email recipients is required")
+	}
+	return &Email{
+		address:    address,
+		host:       host,
+		security:   security,
+		username:   cfg.Username,
+		password:   cfg.Password,
+		from:       from,
+		recipients: recipients,
+	}, nil
+}
+
+func (e *Email) Send(message Message) error {
+	if message == nil {
+		return errors.New("email message is required")
+	}
+	body, err := e.compose(message)
+	if err != nil {
+		return fmt.Errorf("composing email: %w", err)
+	}
+	client, err := e.dial()
+	if err != nil {
+		return fmt.Errorf("connecting to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(e.from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	for _, recipient := range e.recipients {
+		if err := client.Rcpt(recipient.Address); err != nil {
+			return fmt.Errorf("smtp rcpt to %s: %w", recipient.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("writing email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("writing email: %w", err)
+	}
+	return client.Quit()
+}
+
+func (e *Email) dial() (*smtp.Client, error) {
+	dialer := &net.Dialer{Timeout: emailTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if e.security == emailSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", e.address, &tls.Config{ServerName: e.host})
+	} else {
+		conn, err = dialer.Dial("tcp", e.address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(emailTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if e.security == emailSecurityStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+	return client, nil
+}
+
+func (e *Email) compose(message Message) ([]byte, error) {
+	text := message.String()
+	// The HTML part keeps the line breaks of the text.
+	htmlBody := "<pre style=\"font-family: inherit; white-space: pre-wrap\">" + html.EscapeString(text) + "</pre>"
+
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+	to := make([]string, 0, len(e.recipients))
+	for _, recipient := range e.recipients {
+		to = append(to, recipient.String())
+	}
+	fmt.Fprintf(&buf, "From: %s\r\n", e.from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", emailSubject(text)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", body.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{contentType: "text/plain; charset=utf-8", content: text},
+		{contentType: "text/html; charset=utf-8", content: htmlBody},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// emailSubject uses the first line of the message, which is a title for the
+// built-in message types.
+func emailSubject(text string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return "Sigmo notification"
+	}
+	if len([]rune(subject)) > 78 {
+		subject = string([]rune(subject)[:78])
+	}
+	return subject
+}
@@ -1,6 +1,7 @@
 package notify
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -18,7 +19,27 @@ func (m TextMessage) Markdown() string {
 	return escapeMarkdownV2(m.Text)
 }
 
+// FormattedMessage replaces the text rendering of a message, e.g. with the
+// output of a user defined template, while keeping its JSON representation.
+type FormattedMessage struct {
+	Message
+	Text string
+}
+
+func (m FormattedMessage) String() string {
+	return m.Text
+}
+
+func (m FormattedMessage) Markdown() string {
+	return escapeMarkdownV2(m.Text)
+}
+
+func (m FormattedMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Message)
+}
+
 type SMSMessage struct {
+	ModemID  string    `json:"-"`
 	Modem    string    `json:"modem"`
 	From     string    `json:"from"`
 	To       string    `json:"to"`
@@ -28,8 +49,13 @@ type SMSMessage struct {
 }
 
 func (m SMSMessage) String() string {
+	title := "SMS received"
+	if !m.Incoming {
+		title = "SMS sent"
+	}
 	return fmt.Sprintf(
-		"SMS received\nModem: %s\nFrom: %s\nTo: %s\nTime: %s\n\n%s",
+		"%s\nModem: %s\nFrom: %s\nTo: %s\nTime: %s\n\n%s",
+		title,
 		m.Modem,
 		m.From,
 		m.To,
@@ -49,6 +75,14 @@ func (m SMSMessage) Markdown() string {
 	)
 }
 
+// Participant returns the remote party of the message.
+func (m SMSMessage) Participant() string {
+	if m.Incoming {
+		return m.From
+	}
+	return m.To
+}
+
 func (m SMSMessage) displayText() string {
 	text := strings.TrimSpace(m.Text)
 	if text == "" {
@@ -64,6 +98,77 @@ func (m SMSMessage) displayTimestamp() string {
 	return m.Time.Format(time.RFC3339)
 }
 
+const (
+	CallEventIncoming = "incoming"
+	CallEventMissed   = "missed"
+	CallEventAnswered = "answered"
+)
+
+// CallMessage reports an incoming call: when it rings (incoming) and when it
+// ends unanswered (missed) or after it was answered (answered).
+type CallMessage struct {
+	ModemID  string        `json:"-"`
+	Modem    string        `json:"modem"`
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Time     time.Time     `json:"timestamp"`
+	Event    string        `json:"event"`
+	Answered bool          `json:"answered"`
+	Duration time.Duration `json:"-"`
+}
+
+// MarshalJSON adds a type, so HTTP receivers can tell calls from SMS, and
+// reports the duration in seconds.
+func (m CallMessage) MarshalJSON() ([]byte, error) {
+	type message CallMessage
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		message
+		Duration int64 `json:"duration"`
+	}{Type: "call", message: message(m), Duration: int64(m.Duration / time.Second)})
+}
+
+func (m CallMessage) title() string {
+	switch m.Event {
+	case CallEventMissed:
+		return "Missed call"
+	case CallEventAnswered:
+		return "Answered call"
+	default:
+		return "Incoming call"
+	}
+}
+
+func (m CallMessage) String() string {
+	text := fmt.Sprintf(
+		"%s\nModem: %s\nFrom: %s\nTo: %s\nTime: %s",
+		m.title(),
+		m.Modem,
+		m.From,
+		m.To,
+		m.Time.Format(time.RFC3339),
+	)
+	if m.Answered {
+		text += fmt.Sprintf("\nDuration: %s", m.Duration)
+	}
+	return text
+}
+
+func (m CallMessage) Markdown() string {
+	text := fmt.Sprintf(
+		"*%s*\n*Modem:* %s\n*From:* %s\n*To:* %s\n*Time:* %s",
+		m.title(),
+		escapeMarkdownV2(m.Modem),
+		escapeMarkdownV2(m.From),
+		escapeMarkdownV2(m.To),
+		escapeMarkdownV2(m.Time.Format(time.RFC3339)),
+	)
+	if m.Answered {
+		text += fmt.Sprintf("\n*Duration:* %s", escapeMarkdownV2(m.Duration.String()))
+	}
+	return text
+}
+
 var markdownV2Escaper = strings.NewReplacer(
 	"\\", "\\\\",
 	"_", "\\_",
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/damonto/sigmo/internal/pkg/config"
@@ -30,8 +31,9 @@ type Notifier struct {
 	cfg      *config.Config
 }
 
-// New creates a new Notifier from the given configuration.
-func New(cfg *config.Config) (*Notifier, error) {
+// New creates a new Notifier from the given configuration. SMS forwarded to
+// Telegram are remembered in replies, unless it is nil.
+func New(cfg *config.Config, replies *TelegramReplies) (*Notifier, error) {
 	if cfg == nil || len(cfg.Channels) == 0 {
 		return &Notifier{
 			channels: make(map[string]Sender),
@@ -42,7 +44,7 @@ func New(cfg *config.Config) (*Notifier, error) {
 	channels := make(map[string]Sender)
 	for name, channel := range cfg.Channels {
 		channelName := strings.ToLower(name)
-		sender, err := createSender(channelName, channel)
+		sender, err := createSender(cfg.ChannelType(name), channel, replies)
 		if err != nil {
 			return nil, fmt.Errorf("creating %s channel: %w", name, err)
 		}
@@ -52,17 +54,29 @@ func New(cfg *config.Config) (*Notifier, error) {
 	return &Notifier{channels: channels, cfg: cfg}, nil
 }
 
-func createSender(name string, channel config.Channel) (Sender, error) {
-	switch name {
+func createSender(channelType string, channel config.Channel, replies *TelegramReplies) (Sender, error) {
+	switch channelType {
 	case "telegram":
-		return NewTelegram(&channel)
+		return NewTelegram(&channel, replies)
 	case "http":
 		return NewHTTP(&channel)
+	case "email":
+		return NewEmail(&channel)
 	default:
-		return nil, fmt.Errorf("unsupported channel type: %s", name)
+		return nil, fmt.Errorf("unsupported channel type: %s", channelType)
 	}
 }
 
+// Channels returns the names of the configured channels.
+func (n *Notifier) Channels() []string {
+	names := make([]string, 0, len(n.channels))
+	for name := range n.channels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
 // Send sends a message to the specified channels.
 // If no channels are specified, the message will be sent to all configured channels.
 func (n *Notifier) Send(message Message, channels ...string) error {
@@ -1,29 +1,28 @@
 package notify
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"log/slog"
-	"net/http"
-	"net/url"
-	"path"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/telegram"
 )
 
-const defaultTelegramEndpoint = "https://api.telegram.org"
 const telegramParseModeMarkdownV2 = "MarkdownV2"
 
+const telegramSendTimeout = 10 * time.Second
+
+// telegramRepliesLimit bounds how many forwarded SMS are remembered for
+// answering from Telegram.
+const telegramRepliesLimit = 1024
+
 type Telegram struct {
-	client     *http.Client
-	baseURL    url.URL
-	botToken   string
+	client     *telegram.Client
 	recipients []int64
+	replies    *TelegramReplies
 }
 
 type telegramMessage struct {
@@ -32,23 +31,57 @@ type telegramMessage struct {
 	ParseMode string `json:"parse_mode,omitempty"`
 }
 
-func NewTelegram(cfg *config.Channel) (*Telegram, error) {
+type telegramSent struct {
+	MessageID int64 `json:"message_id"`
+}
+
+type telegramReplyKey struct {
+	botToken  string
+	chatID    int64
+	messageID int64
+}
+
+// TelegramReplies remembers the SMS forwarded to Telegram by the message
+// they were forwarded as, so that a reply to one can be answered from the
+// same modem.
+type TelegramReplies struct {
+	mu       sync.Mutex
+	messages map[telegramReplyKey]SMSMessage
+	order    []telegramReplyKey
+}
+
+func NewTelegramReplies() *TelegramReplies {
+	return &TelegramReplies{messages: make(map[telegramReplyKey]SMSMessage)}
+}
+
+// Get returns the SMS that was forwarded as the given Telegram message.
+func (r *TelegramReplies) Get(botToken string, chatID int64, messageID int64) (SMSMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message, ok := r.messages[telegramReplyKey{botToken: botToken, chatID: chatID, messageID: messageID}]
+	return message, ok
+}
+
+func (r *TelegramReplies) remember(key telegramReplyKey, message SMSMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.order) >= telegramRepliesLimit {
+		delete(r.messages, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.messages[key] = message
+	r.order = append(r.order, key)
+}
+
+// NewTelegram returns a channel sending to the recipients of the bot. The
+// forwarded SMS are remembered in replies, unless it is nil.
+func NewTelegram(cfg *config.Channel, replies *TelegramReplies) (*Telegram, error) {
 	if cfg == nil {
 		return nil, errors.New("telegram config is required")
 	}
-	if strings.TrimSpace(cfg.BotToken) == "" {
-		return nil, errors.New("telegram bot token is required")
-	}
-	endpoint := strings.TrimSpace(cfg.Endpoint)
-	if endpoint == "" {
-		endpoint = defaultTelegramEndpoint
-	}
-	baseURL, err := url.Parse(endpoint)
+	client, err := telegram.New(cfg.Endpoint, cfg.BotToken)
 	if err != nil {
-		return nil, fmt.Errorf("parsing telegram endpoint: %w", err)
-	}
-	if baseURL.Scheme == "" || baseURL.Host == "" {
-		return nil, errors.New("telegram endpoint must include scheme and host")
+		return nil, err
 	}
 	recipients, err := cfg.Recipients.Int64s()
 	if err != nil {
@@ -58,10 +91,9 @@ func NewTelegram(cfg *config.Channel) (*Telegram, error) {
 		return nil, errors.New("telegram recipients is required")
 	}
 	return &Telegram{
-		client:     &http.Client{Timeout: 10 * time.Second},
-		baseURL:    *baseURL,
-		botToken:   cfg.BotToken,
+		client:     client,
 		recipients: recipients,
+		replies:    replies,
 	}, nil
 }
 
@@ -74,40 +106,38 @@ func (t *Telegram) Send(message Message) error {
 	}
 	var combined error
 	payload := message.Markdown()
+	sms, replyable := smsMessage(message)
 	for _, recipient := range t.recipients {
-		if err := t.sendOne(recipient, payload); err != nil {
+		messageID, err := t.sendOne(recipient, payload)
+		if err != nil {
 			combined = errors.Join(combined, err)
+			continue
+		}
+		if replyable && messageID != 0 && t.replies != nil {
+			t.replies.remember(telegramReplyKey{botToken: t.client.BotToken(), chatID: recipient, messageID: messageID}, sms)
 		}
 	}
 	return combined
 }
 
-func (t *Telegram) sendOne(to int64, text string) error {
-	message := telegramMessage{
+func smsMessage(message Message) (SMSMessage, bool) {
+	if formatted, ok := message.(FormattedMessage); ok {
+		message = formatted.Message
+	}
+	sms, ok := message.(SMSMessage)
+	return sms, ok && sms.ModemID != ""
+}
+
+func (t *Telegram) sendOne(to int64, text string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), telegramSendTimeout)
+	defer cancel()
+	var sent telegramSent
+	if err := t.client.Call(ctx, "sendMessage", telegramMessage{
 		ChatID:    to,
 		Text:      text,
 		ParseMode: telegramParseModeMarkdownV2,
+	}, &sent); err != nil {
+		return 0, err
 	}
-	body, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("encoding telegram message: %w", err)
-	}
-	endpoint := t.baseURL
-	endpoint.Path = path.Join(endpoint.Path, "bot"+t.botToken, "sendMessage")
-	req, err := http.NewRequest(http.MethodPost, endpoint.String(), bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("building telegram request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := t.client.Do(req)
-	if err != nil {
-		slog.Error("failed to send telegram message", "recipient", to, "error", err)
-		return errors.New("telegram API request failed")
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return fmt.Errorf("telegram response status %s: %s", resp.Status, strings.TrimSpace(string(payload)))
-	}
-	return nil
+	return sent.MessageID, nil
 }
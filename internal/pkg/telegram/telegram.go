@@ -0,0 +1,102 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const DefaultEndpoint = "https://api.telegram.org"
+
+// requestTimeout must outlast the long polling of getUpdates, callers bound
+// shorter requests with their context.
+const requestTimeout = time.Minute
+
+// Client calls the Bot API of a single bot.
+type Client struct {
+	client   *http.Client
+	baseURL  url.URL
+	botToken string
+}
+
+type response struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// New returns a client for the bot. An empty endpoint uses the public Bot
+// API.
+func New(endpoint string, botToken string) (*Client, error) {
+	if strings.TrimSpace(botToken) == "" {
+		return nil, errors.New("telegram bot token is required")
+	}
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	baseURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing telegram endpoint: %w", err)
+	}
+	if baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, errors.New("telegram endpoint must include scheme and host")
+	}
+	return &Client{
+		client:   &http.Client{Timeout: requestTimeout},
+		baseURL:  *baseURL,
+		botToken: botToken,
+	}, nil
+}
+
+// BotToken returns the token of the bot, which identifies it.
+func (c *Client) BotToken() string {
+	return c.botToken
+}
+
+// Call invokes the Bot API method with the JSON encoded payload and decodes
+// its result into result, unless it is nil.
+func (c *Client) Call(ctx context.Context, method string, payload any, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding telegram %s request: %w", method, err)
+	}
+	endpoint := c.baseURL
+	endpoint.Path = path.Join(endpoint.Path, "bot"+c.botToken, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building telegram %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// The request URL carries the bot token, keep it out of the error.
+		return fmt.Errorf("telegram %s request failed", method)
+	}
+	defer resp.Body.Close()
+	var decoded response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decoded); err != nil {
+		return fmt.Errorf("decoding telegram %s response (status %s): %w", method, resp.Status, err)
+	}
+	if !decoded.OK {
+		return fmt.Errorf("telegram %s failed: %s", method, decoded.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(decoded.Result, result); err != nil {
+		return fmt.Errorf("decoding telegram %s result: %w", method, err)
+	}
+	return nil
+}
@@ -1,33 +1,49 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/damonto/sigmo/internal/app/archive"
+	"github.com/damonto/sigmo/internal/app/auth"
+	"github.com/damonto/sigmo/internal/app/bot"
+	"github.com/damonto/sigmo/internal/app/dispatcher"
+	"github.com/damonto/sigmo/internal/app/events"
 	"github.com/damonto/sigmo/internal/app/forwarder"
+	"github.com/damonto/sigmo/internal/app/history"
+	appmiddleware "github.com/damonto/sigmo/internal/app/middleware"
 	"github.com/damonto/sigmo/internal/app/router"
+	"github.com/damonto/sigmo/internal/app/unlock"
 	"github.com/damonto/sigmo/internal/pkg/config"
+	"github.com/damonto/sigmo/internal/pkg/metrics"
 	"github.com/damonto/sigmo/internal/pkg/modem"
+	"github.com/damonto/sigmo/internal/pkg/notify"
 	"github.com/damonto/sigmo/internal/pkg/validator"
 )
 
 var (
 	BuildVersion string
 	configPath   string
+	encrypt      bool
 )
 
 func init() {
 	flag.StringVar(&configPath, "config", "config.toml", "path to config file")
+	flag.BoolVar(&encrypt, "encrypt", false, "encrypt a secret read from stdin, e.g. a SIM PIN, and exit")
 }
 
 func main() {
@@ -37,6 +53,13 @@ func main() {
 		slog.Error("unable to load config", "error", err)
 		os.Exit(1)
 	}
+	if encrypt {
+		if err := encryptSecret(cfg); err != nil {
+			slog.Error("unable to encrypt secret", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if !cfg.IsProduction() {
 		slog.SetLogLoggerLevel(slog.LevelDebug)
 	}
@@ -48,27 +71,86 @@ func main() {
 		os.Exit(1)
 	}
 
+	messages, err := archive.Open(cfg.DataPath("messages.db"))
+	if err != nil {
+		slog.Error("unable to open message archive", "error", err)
+		os.Exit(1)
+	}
+	defer messages.Close()
+
+	sessions, err := auth.Open(cfg.DataPath("sessions.db"))
+	if err != nil {
+		slog.Error("unable to open session store", "error", err)
+		os.Exit(1)
+	}
+	defer sessions.Close()
+	if err := sessions.AddConfigKeys(cfg.APIKeys); err != nil {
+		slog.Error("invalid api keys", "error", err)
+		os.Exit(1)
+	}
+
+	samples, err := history.Open(cfg.DataPath("history.db"))
+	if err != nil {
+		slog.Error("unable to open signal history", "error", err)
+		os.Exit(1)
+	}
+	defer samples.Close()
+
+	hub := events.NewHub()
+
 	server := echo.New()
 	server.HideBanner = true
 	server.Validator = validator.New()
+	server.IPExtractor, err = ipExtractor(cfg.App.TrustedProxies)
+	if err != nil {
+		slog.Error("invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
 	if !cfg.IsProduction() {
 		server.Use(middleware.RequestLogger())
 	}
 	server.Use(middleware.RequestID())
+	server.Use(appmiddleware.Metrics())
 	server.Use(middleware.Recover())
 	server.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch, http.MethodHead, http.MethodOptions},
 		AllowHeaders: []string{"*"},
 	}))
-	router.Register(server, cfg, manager)
+	notifications, err := dispatcher.New(cfg, manager, hub)
+	if err != nil {
+		slog.Error("unable to configure notification dispatcher", "error", err)
+		os.Exit(1)
+	}
+	defer notifications.Close()
+
+	router.Register(server, cfg, manager, messages, sessions, hub, samples, notifications)
+	if err := metrics.Register(metrics.NewModemCollector(cfg, manager)); err != nil {
+		slog.Error("unable to register modem metrics", "error", err)
+		os.Exit(1)
+	}
 
-	relay, err := forwarder.New(cfg, manager)
+	// Telegram replies to forwarded SMS are answered by the bot.
+	replies := notify.NewTelegramReplies()
+	relay, err := forwarder.New(cfg, manager, replies)
 	if err != nil {
 		slog.Error("unable to configure message relay", "error", err)
 		os.Exit(1)
 	}
 
+	telegramBot, err := bot.New(cfg, manager, messages, hub, replies)
+	if err != nil {
+		slog.Error("unable to configure telegram bot", "error", err)
+		os.Exit(1)
+	}
+
+	unlocker, err := unlock.New(cfg, manager)
+	if err != nil {
+		slog.Error("unable to configure SIM unlocking", "error", err)
+		os.Exit(1)
+	}
+	defer unlocker.Close()
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
@@ -81,6 +163,52 @@ func main() {
 		}()
 	}
 
+	if telegramBot.Enabled() {
+		go func() {
+			if err := telegramBot.Run(ctx); err != nil {
+				slog.Error("telegram bot stopped", "error", err)
+				stop()
+			}
+		}()
+	}
+
+	if unlocker.Enabled() {
+		go func() {
+			if err := unlocker.Run(ctx); err != nil {
+				slog.Error("SIM unlocker stopped", "error", err)
+				stop()
+			}
+		}()
+	}
+
+	go func() {
+		if err := events.NewWatcher(cfg, hub, manager).Run(ctx); err != nil {
+			slog.Error("event watcher stopped", "error", err)
+			stop()
+		}
+	}()
+
+	go func() {
+		if err := history.NewSampler(cfg, samples, manager).Run(ctx); err != nil {
+			slog.Error("signal sampler stopped", "error", err)
+			stop()
+		}
+	}()
+
+	go func() {
+		if err := notifications.Run(ctx); err != nil {
+			slog.Error("notification dispatcher stopped", "error", err)
+			stop()
+		}
+	}()
+
+	go func() {
+		if err := archive.NewIngester(messages, manager, hub).Run(ctx); err != nil {
+			slog.Error("message archive stopped", "error", err)
+			stop()
+		}
+	}()
+
 	go func() {
 		if err := server.Start(cfg.App.ListenAddress); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			slog.Error("http server stopped", "error", err)
@@ -96,3 +224,55 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// encryptSecret prints the first line of stdin encrypted with the secret key,
+// ready to be pasted into the config file.
+func encryptSecret(cfg *config.Config) error {
+	key, err := cfg.SecretKey()
+	if err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("reading secret: %w", err)
+	}
+	secret := strings.TrimSpace(line)
+	if secret == "" {
+		return errors.New("secret is empty")
+	}
+	encrypted, err := config.Encrypt(key, secret)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encrypted)
+	return nil
+}
+
+// ipExtractor only trusts X-Forwarded-For when it is set by one of the
+// configured proxies, so clients cannot pick their own IP.
+func ipExtractor(proxies []string) (echo.IPExtractor, error) {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing trusted proxy %q: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}